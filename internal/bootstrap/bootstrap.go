@@ -1,21 +1,39 @@
 package bootstrap
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
 	"github.com/khedhrije/podcaster-indexer-api/internal/infrastructure/elasticsearchv7"
 	"github.com/khedhrije/podcaster-indexer-api/internal/infrastructure/mysql"
+	"github.com/khedhrije/podcaster-indexer-api/internal/infrastructure/nats"
+	"github.com/khedhrije/podcaster-indexer-api/internal/infrastructure/webhook"
 	"github.com/khedhrije/podcaster-indexer-api/internal/ui/gin/handlers"
 	"github.com/khedhrije/podcaster-indexer-api/internal/ui/gin/router"
 	"github.com/rs/zerolog/log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout is the time given to the requests and the webhook deliveries in flight to complete on shutdown.
+const shutdownTimeout = 30 * time.Second
+
 // Bootstrap struct encapsulates the configuration settings and the HTTP router necessary for the application to run.
 type Bootstrap struct {
-	Config *configuration.AppConfig // Application configuration settings
-	Router *gin.Engine              // HTTP router for handling web requests
+	Config        *configuration.AppConfig // Application configuration settings
+	Router        *gin.Engine              // HTTP router for handling web requests
+	Scheduler     *Scheduler               // Scheduler triggering periodic reindexing
+	LeaderElector *LeaderElector           // Leader election among replicas, nil when disabled
+	Consumer      *CommandConsumer         // Consumer of the indexation commands, nil when disabled
+	OutboxPoller  *OutboxPoller            // Poller of the MySQL outbox, nil when disabled
+	ChangeCapture *ChangeCapture           // Change data capture from the MySQL binlog, nil when disabled
+	Notifier      *webhook.Notifier        // Notifier of the outbound webhooks, drained on shutdown
 }
 
 // InitBootstrap initializes the bootstrap process and returns a Bootstrap instance.
@@ -42,6 +60,10 @@ func initBootstrap() Bootstrap {
 	if err != nil {
 		panic("could not init elasticsearch client")
 	}
+	esSearcher, err := elasticsearchv7.NewSearchAdapter(app.Config)
+	if err != nil {
+		panic("could not init elasticsearch search adapter")
+	}
 
 	// Initialize MySQL adapters for different domain models, setting up the data access layer
 	catPersistenceAdapter := mysql.NewCategoryAdapter(mysqlClient)
@@ -51,26 +73,141 @@ func initBootstrap() Bootstrap {
 	programPersistenceAdapter := mysql.NewProgramAdapter(mysqlClient)
 	episodePersistenceAdapter := mysql.NewEpisodeAdapter(mysqlClient)
 	mediaPersistenceAdapter := mysql.NewMediaAdapter(mysqlClient)
+	historyPersistenceAdapter := mysql.NewHistoryAdapter(mysqlClient)
+	searchTermsPersistenceAdapter := mysql.NewSearchTermsAdapter(mysqlClient)
+	leasePersistenceAdapter := mysql.NewLeaseAdapter(mysqlClient)
+
+	// Initialize the notifier delivering the outcome of the indexations to the outbound webhooks
+	webhookNotifier, err := webhook.NewNotifier(app.Config.Webhooks)
+	if err != nil {
+		log.Panic().Err(err).Msg("could not init webhook notifier")
+	}
+	app.Notifier = webhookNotifier
+
+	options := []api.Option{
+		api.WithTimeouts(app.Config.Indexation.DefaultTimeout, app.Config.Indexation.Timeouts),
+		api.WithQualitySeverities(app.Config.Indexation.Severities),
+		api.WithTextNormalization(app.Config.Indexation.SummaryLength, app.Config.Indexation.KeepRawText),
+		api.WithLanguages(app.Config.Indexation.Languages, app.Config.Indexation.DetectLanguage),
+		api.WithBuiltinEnrichers(app.Config.Indexation.Enrichers),
+		api.WithHistory(historyPersistenceAdapter),
+		api.WithSearchTerms(searchTermsPersistenceAdapter),
+		api.WithNotifiers(webhookNotifier),
+	}
+	// The jobs hold a lease of their docs type only when several replicas share the database
+	if app.Config.Indexation.JobLease {
+		options = append(options, api.WithJobLease(leasePersistenceAdapter, leaseHolder(app.Config.Scheduler.LeaderElection), app.Config.Scheduler.LeaderElection.TTL))
+	}
+
+	// Initialize the NATS publisher of the index events, if a broker is configured
+	var natsConsumer port.Consumer
+	if app.Config.Nats.URL != "" {
+		natsClient, err := nats.NewClient(app.Config.Nats)
+		if err != nil {
+			log.Panic().Err(err).Msg("could not init nats client")
+		}
+		options = append(options, api.WithPublishers(nats.NewPublisherAdapter(natsClient)))
+		if app.Config.Nats.Consumer.Enabled {
+			natsConsumer = nats.NewConsumerAdapter(natsClient)
+		}
+	}
 
 	// Initialize APIs for different domain models, enabling business logic operations
-	indexationApi := api.NewIndexerApi(esClient, wallPersistenceAdapter, catPersistenceAdapter, tagPersistenceAdapter, blockPersistenceAdapter, programPersistenceAdapter, episodePersistenceAdapter, mediaPersistenceAdapter)
+	indexationApi := api.NewIndexerApi(esClient, wallPersistenceAdapter, catPersistenceAdapter, tagPersistenceAdapter, blockPersistenceAdapter, programPersistenceAdapter, episodePersistenceAdapter, mediaPersistenceAdapter,
+		options...,
+	)
+	searchApi := api.NewSearchApi(esSearcher,
+		api.WithFederatedSearch(app.Config.Search.GroupSize, app.Config.Search.TopHits, app.Config.Search.Boosts),
+	)
+
+	// Initialize the consumer of the indexation commands, if any
+	if natsConsumer != nil {
+		app.Consumer = NewCommandConsumer(natsConsumer, indexationApi)
+	}
+
+	// Initialize the poller of the MySQL outbox, if enabled
+	if app.Config.Outbox.Enabled {
+		outboxPoller, err := NewOutboxPoller(mysql.NewOutboxAdapter(mysqlClient), indexationApi, app.Config.Outbox)
+		if err != nil {
+			log.Panic().Err(err).Msg("could not init outbox poller")
+		}
+		app.OutboxPoller = outboxPoller
+	}
+
+	// Initialize the leader election, so that only one replica triggers scheduled runs and captures the binlog
+	var leadership Leadership
+	if app.Config.Scheduler.LeaderElection.Enabled {
+		app.LeaderElector = NewLeaderElector(leasePersistenceAdapter, app.Config.Scheduler.LeaderElection)
+		leadership = app.LeaderElector
+	}
+
+	// Initialize the change data capture from the MySQL binlog, if enabled
+	if app.Config.Binlog.Enabled {
+		app.ChangeCapture = NewChangeCapture(mysql.NewBinlogAdapter(mysqlClient, app.Config), indexationApi, leadership)
+	}
+
+	// Initialize the scheduler triggering periodic reindexing
+	scheduler, err := NewScheduler(app.Config.Scheduler, indexationApi, leadership)
+	if err != nil {
+		log.Panic().Err(err).Msg("could not init scheduler")
+	}
+	app.Scheduler = scheduler
 
 	// Initialize handlers for different APIs, setting up the presentation layer
 	indexationHandler := handlers.NewIndexationHandler(indexationApi)
+	schedulingHandler := handlers.NewSchedulingHandler(scheduler)
+	webhooksHandler := handlers.NewWebhooksHandler(webhookNotifier)
+	searchHandler := handlers.NewSearchHandler(searchApi)
 
 	// Create the router with the initialized handlers, configuring the request handling
 	r := router.CreateRouter(
 		indexationHandler,
+		schedulingHandler,
+		webhooksHandler,
+		searchHandler,
 	)
 	app.Router = r
 	return app
 }
 
-// Run starts the application by running the HTTP server on the configured host address and port.
+// Run starts the application by running the HTTP server on the configured host address and port, until the process
+// is interrupted or terminated. On shutdown, the server stops taking requests, the background workers are stopped
+// and the pending webhook deliveries are given shutdownTimeout to complete.
 // It logs a fatal error if the server cannot be started, ensuring that the failure is captured and reported.
 func (b Bootstrap) Run() {
-	dsn := fmt.Sprintf("%s:%d", b.Config.HostAddress, b.Config.HostPort)
-	if errRun := b.Router.Run(dsn); errRun != nil {
-		log.Fatal().Msg("error during service instantiation")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if b.LeaderElector != nil {
+		go b.LeaderElector.Run(ctx)
+	}
+	if b.Consumer != nil {
+		go b.Consumer.Run(ctx)
+	}
+	if b.OutboxPoller != nil {
+		go b.OutboxPoller.Run(ctx)
+	}
+	if b.ChangeCapture != nil {
+		go b.ChangeCapture.Run(ctx)
+	}
+
+	b.Scheduler.Start()
+
+	server := &http.Server{Addr: fmt.Sprintf("%s:%d", b.Config.HostAddress, b.Config.HostPort), Handler: b.Router}
+	go func() {
+		if errRun := server.ListenAndServe(); errRun != nil && !errors.Is(errRun, http.ErrServerClosed) {
+			log.Fatal().Err(errRun).Msg("error during service instantiation")
+		}
+	}()
+	<-ctx.Done()
+	log.Info().Msg("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("could not stop the http server")
+	}
+	b.Scheduler.Stop()
+	if err := b.Notifier.Drain(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("could not drain the webhook deliveries")
 	}
 }
@@ -0,0 +1,98 @@
+package bootstrap
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// captureRestartDelay is the delay before streaming again after the change stream stopped on an error.
+const captureRestartDelay = 5 * time.Second
+
+// leadershipCheckInterval is the delay between two checks of the leadership of the change capture.
+const leadershipCheckInterval = time.Second
+
+// ChangeCapture feeds the changes captured from the database to the indexer.
+// With a leadership, only the leader replica streams the changes, so that a single replica replicates the binlog.
+type ChangeCapture struct {
+	stream        port.ChangeStream
+	indexer       api.Indexer
+	leadership    Leadership
+	checkInterval time.Duration // Delay between two checks of the leadership
+}
+
+// NewChangeCapture creates a change capture applying the changes of the stream with the indexer.
+// The leadership is optional: without it, the changes are always streamed.
+func NewChangeCapture(stream port.ChangeStream, indexer api.Indexer, leadership Leadership) *ChangeCapture {
+	return &ChangeCapture{
+		stream:        stream,
+		indexer:       indexer,
+		leadership:    leadership,
+		checkInterval: leadershipCheckInterval,
+	}
+}
+
+// Run streams the changes until the context is done, streaming again from the last checkpoint after a delay
+// when the stream or the indexer fails. The stream only runs while the replica is the leader: it is stopped
+// as soon as the leadership is lost, and started again once it is regained.
+func (c *ChangeCapture) Run(ctx context.Context) {
+	for {
+		if !c.isLeader() {
+			if !sleep(ctx, c.checkInterval) {
+				return
+			}
+			continue
+		}
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		go c.watchLeadership(streamCtx, cancel)
+		err := c.stream.Stream(streamCtx, c.indexer.Apply)
+		lost := streamCtx.Err() != nil
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if lost {
+			log.Info().Msg("leadership lost, change capture stopped")
+			continue
+		}
+		log.Error().Err(err).Msg("change capture stopped, restarting")
+		if !sleep(ctx, captureRestartDelay) {
+			return
+		}
+	}
+}
+
+// isLeader reports whether the changes may be streamed by this replica.
+func (c *ChangeCapture) isLeader() bool {
+	return c.leadership == nil || c.leadership.IsLeader()
+}
+
+// watchLeadership cancels the stream as soon as the leadership is lost, until the context is done.
+func (c *ChangeCapture) watchLeadership(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(c.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !c.isLeader() {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// sleep waits for the delay and reports whether the context is still running.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(delay):
+		return true
+	}
+}
@@ -0,0 +1,66 @@
+package bootstrap
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLeadership is a Leadership switched by the test.
+type fakeLeadership struct {
+	leader atomic.Bool
+}
+
+func (f *fakeLeadership) IsLeader() bool {
+	return f.leader.Load()
+}
+
+// fakeChangeIndexer is an api.Indexer ignoring the changes.
+type fakeChangeIndexer struct {
+	api.Indexer
+}
+
+func (f *fakeChangeIndexer) Apply(ctx context.Context, change model.Change) error {
+	return nil
+}
+
+// fakeChangeStream is a port.ChangeStream counting the streams, which run until their context is done.
+type fakeChangeStream struct {
+	streams atomic.Int32
+	running atomic.Bool
+}
+
+func (f *fakeChangeStream) Stream(ctx context.Context, handler port.ChangeHandler) error {
+	f.streams.Add(1)
+	f.running.Store(true)
+	defer f.running.Store(false)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func Test_Run_WhenLeadershipChanges(t *testing.T) {
+	leadership := &fakeLeadership{}
+	stream := &fakeChangeStream{}
+	capture := NewChangeCapture(stream, &fakeChangeIndexer{}, leadership)
+	capture.checkInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go capture.Run(ctx)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, stream.streams.Load(), "Should not stream before the leadership is acquired")
+
+	leadership.leader.Store(true)
+	assert.Eventually(t, stream.running.Load, time.Second, time.Millisecond, "Should stream once leader")
+
+	leadership.leader.Store(false)
+	assert.Eventually(t, func() bool { return !stream.running.Load() }, time.Second, time.Millisecond, "Should stop once the leadership is lost")
+
+	leadership.leader.Store(true)
+	assert.Eventually(t, func() bool { return stream.streams.Load() == 2 }, time.Second, time.Millisecond, "Should stream again once leader again")
+}
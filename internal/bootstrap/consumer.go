@@ -0,0 +1,42 @@
+package bootstrap
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// consumerRestartDelay is the delay before consuming again after the consumer stopped on an error.
+const consumerRestartDelay = 5 * time.Second
+
+// CommandConsumer feeds the indexation commands received from a message queue to the indexer.
+type CommandConsumer struct {
+	consumer port.Consumer
+	indexer  api.Indexer
+}
+
+// NewCommandConsumer creates a command consumer passing the commands of the consumer to the indexer.
+func NewCommandConsumer(consumer port.Consumer, indexer api.Indexer) *CommandConsumer {
+	return &CommandConsumer{
+		consumer: consumer,
+		indexer:  indexer,
+	}
+}
+
+// Run consumes the commands until the context is done, consuming again after a delay when the consumer fails.
+func (c *CommandConsumer) Run(ctx context.Context) {
+	for {
+		err := c.consumer.Consume(ctx, c.indexer.Handle)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error().Err(err).Msg("command consumer stopped, restarting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(consumerRestartDelay):
+		}
+	}
+}
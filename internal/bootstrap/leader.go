@@ -0,0 +1,108 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Leadership reports whether this instance may trigger scheduled work.
+type Leadership interface {
+	IsLeader() bool
+}
+
+// LeaderElector campaigns for a lease shared by the replicas; the replica holding it is the leader.
+// The lease is renewed at a third of its TTL, and leadership is lost as soon as a renewal fails or is late.
+type LeaderElector struct {
+	lease  port.Lease
+	name   string
+	holder string
+	ttl    time.Duration
+
+	mu         sync.RWMutex
+	validUntil time.Time // Local deadline of the currently held lease, zero if not the leader
+}
+
+// NewLeaderElector creates a leader elector using the given lease and configuration.
+// The holder defaults to the hostname and process ID of the instance.
+func NewLeaderElector(lease port.Lease, config configuration.LeaderElectionConfig) *LeaderElector {
+	return &LeaderElector{
+		lease:  lease,
+		name:   config.LeaseName,
+		holder: leaseHolder(config),
+		ttl:    config.TTL,
+	}
+}
+
+// leaseHolder returns the identity of this replica in the leases, defaulting to the hostname and process ID of the instance.
+func leaseHolder(config configuration.LeaderElectionConfig) string {
+	if config.Holder != "" {
+		return config.Holder
+	}
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// Run campaigns for the lease until the context is done, then releases it.
+func (e *LeaderElector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader reports whether this instance currently holds a valid lease.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return time.Now().Before(e.validUntil)
+}
+
+// campaign tries to acquire or renew the lease and updates the leadership accordingly.
+func (e *LeaderElector) campaign(ctx context.Context) {
+	wasLeader := e.IsLeader()
+	attemptedAt := time.Now()
+
+	acquired, err := e.lease.Acquire(ctx, e.name, e.holder, e.ttl)
+	if err != nil {
+		log.Error().Err(err).Str("lease", e.name).Msg("could not acquire leader lease")
+		acquired = false
+	}
+
+	e.mu.Lock()
+	e.validUntil = time.Time{}
+	if acquired {
+		e.validUntil = attemptedAt.Add(e.ttl)
+	}
+	e.mu.Unlock()
+
+	if acquired != wasLeader {
+		log.Info().Str("lease", e.name).Str("holder", e.holder).Bool("leader", acquired).Msg("leadership changed")
+	}
+}
+
+// release gives up the lease so that another replica can take over without waiting for its expiry.
+func (e *LeaderElector) release() {
+	e.mu.Lock()
+	e.validUntil = time.Time{}
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.lease.Release(ctx, e.name, e.holder); err != nil {
+		log.Warn().Err(err).Str("lease", e.name).Msg("could not release leader lease")
+	}
+}
@@ -0,0 +1,77 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// OutboxPoller applies the entries of the transactional outbox to the live indexes.
+// Every replica may poll: entries being processed by one are skipped by the others.
+type OutboxPoller struct {
+	outbox  port.Outbox
+	indexer api.Indexer
+	config  configuration.OutboxConfig
+}
+
+// NewOutboxPoller creates a poller applying the entries of the outbox with the indexer.
+// It fails if the interval, batch size or maximum number of attempts is not positive.
+func NewOutboxPoller(outbox port.Outbox, indexer api.Indexer, config configuration.OutboxConfig) (*OutboxPoller, error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("invalid outbox interval %s: must be positive", config.Interval)
+	}
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("invalid outbox batch size %d: must be positive", config.BatchSize)
+	}
+	if config.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("invalid outbox max attempts %d: must be positive", config.MaxAttempts)
+	}
+	return &OutboxPoller{
+		outbox:  outbox,
+		indexer: indexer,
+		config:  config,
+	}, nil
+}
+
+// Run polls the outbox until the context is done. Full batches are processed back to back,
+// and the poller waits for the configured interval once the outbox is drained or fails.
+func (p *OutboxPoller) Run(ctx context.Context) {
+	for {
+		for p.poll(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.config.Interval):
+		}
+	}
+}
+
+// poll processes a batch of entries and reports whether the batch was full, in which case more entries may be pending.
+func (p *OutboxPoller) poll(ctx context.Context) bool {
+	processed, err := p.outbox.Process(ctx, p.config.BatchSize, p.config.MaxAttempts, p.apply)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("could not poll outbox")
+		}
+		return false
+	}
+	if processed > 0 {
+		log.Debug().Int("entries", processed).Msg("outbox entries processed")
+	}
+	return processed == p.config.BatchSize && ctx.Err() == nil
+}
+
+// apply applies a single outbox entry with the indexer.
+func (p *OutboxPoller) apply(ctx context.Context, command model.Command) error {
+	err := p.indexer.Handle(ctx, command)
+	if err != nil {
+		log.Warn().Err(err).Str("command", command.Type).Str("docsType", command.DocsType).Str("id", command.ID).Msg("could not apply outbox entry")
+	}
+	return err
+}
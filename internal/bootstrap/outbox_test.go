@@ -0,0 +1,111 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// fakeOutbox is a port.Outbox passing its pending commands to the handler, up to the limit per call.
+// Failed commands stay pending.
+type fakeOutbox struct {
+	pending []model.Command
+	calls   int
+	err     error
+}
+
+func (f *fakeOutbox) Process(ctx context.Context, limit, maxAttempts int, handler port.CommandHandler) (int, error) {
+	f.calls++
+	if f.err != nil {
+		return 0, f.err
+	}
+	batch := f.pending
+	if len(batch) > limit {
+		batch = batch[:limit]
+	}
+	var failed []model.Command
+	for _, command := range batch {
+		if err := handler(ctx, command); err != nil {
+			failed = append(failed, command)
+		}
+	}
+	f.pending = append(failed, f.pending[len(batch):]...)
+	return len(batch), nil
+}
+
+// fakeCommandIndexer is an api.Indexer recording the handled commands, failing those of the failing docs type.
+type fakeCommandIndexer struct {
+	api.Indexer
+	failing  string
+	commands []model.Command
+}
+
+func (f *fakeCommandIndexer) Handle(ctx context.Context, command model.Command) error {
+	f.commands = append(f.commands, command)
+	if command.DocsType == f.failing {
+		return errors.New("indexing failed")
+	}
+	return nil
+}
+
+func Test_NewOutboxPoller_WhenConfigIsInvalid(t *testing.T) {
+	for name, config := range map[string]configuration.OutboxConfig{
+		"no interval":     {Interval: 0, BatchSize: 10, MaxAttempts: 3},
+		"no batch size":   {Interval: time.Second, BatchSize: 0, MaxAttempts: 3},
+		"no max attempts": {Interval: time.Second, BatchSize: 10, MaxAttempts: 0},
+	} {
+		_, err := NewOutboxPoller(&fakeOutbox{}, nil, config)
+		assert.Error(t, err, name)
+	}
+}
+
+func Test_Poll_WhenBatchIsFull(t *testing.T) {
+	outbox := &fakeOutbox{pending: []model.Command{
+		{Type: model.CommandEntityUpserted, DocsType: api.Programs, ID: "p1"},
+		{Type: model.CommandEntityUpserted, DocsType: api.Programs, ID: "p2"},
+		{Type: model.CommandEntityDeleted, DocsType: api.Episodes, ID: "e1"},
+	}}
+	indexer := &fakeCommandIndexer{}
+	poller, err := NewOutboxPoller(outbox, indexer, configuration.OutboxConfig{Interval: time.Second, BatchSize: 2, MaxAttempts: 3})
+	assert.NoError(t, err)
+
+	assert.True(t, poller.poll(context.Background()), "Should report more entries after a full batch")
+	assert.False(t, poller.poll(context.Background()), "Should report the outbox drained after a partial batch")
+
+	assert.Len(t, indexer.commands, 3)
+	assert.Equal(t, "e1", indexer.commands[2].ID)
+	assert.Empty(t, outbox.pending)
+}
+
+func Test_Poll_WhenEntryFails(t *testing.T) {
+	outbox := &fakeOutbox{pending: []model.Command{
+		{Type: model.CommandEntityUpserted, DocsType: api.Programs, ID: "p1"},
+		{Type: model.CommandEntityUpserted, DocsType: api.Episodes, ID: "e1"},
+	}}
+	poller, err := NewOutboxPoller(outbox, &fakeCommandIndexer{failing: api.Episodes}, configuration.OutboxConfig{Interval: time.Second, BatchSize: 10, MaxAttempts: 3})
+	assert.NoError(t, err)
+
+	poller.poll(context.Background())
+
+	assert.Len(t, outbox.pending, 1, "Should leave the failed entry pending")
+	assert.Equal(t, "e1", outbox.pending[0].ID)
+}
+
+func Test_Run_WhenOutboxFails(t *testing.T) {
+	outbox := &fakeOutbox{err: errors.New("connection refused")}
+	poller, err := NewOutboxPoller(outbox, &fakeCommandIndexer{}, configuration.OutboxConfig{Interval: 10 * time.Millisecond, BatchSize: 10, MaxAttempts: 3})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+
+	poller.Run(ctx)
+
+	assert.GreaterOrEqual(t, outbox.calls, 2, "Should keep polling at the interval after a failure")
+	assert.LessOrEqual(t, outbox.calls, 7, "Should wait for the interval between failed polls")
+}
@@ -0,0 +1,187 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scheduler triggers the indexations configured with cron expressions.
+// A tick is skipped while the previous run of the same schedule is still active,
+// and on replicas that are not the leader when leader election is used.
+type Scheduler struct {
+	cron       *cron.Cron
+	indexer    api.Indexer
+	leadership Leadership
+	enabled    atomic.Bool
+	runs       []*scheduledRun
+}
+
+// scheduledRun holds the state of a single configured schedule.
+type scheduledRun struct {
+	entryID cron.EntryID
+	config  configuration.ScheduleConfig
+	running atomic.Bool
+
+	mu          sync.Mutex
+	lastSuccess time.Time // Start time of the last successful run, lower bound of delta runs without history
+}
+
+// NewScheduler creates a scheduler for the configured runs. A nil leadership means this instance always triggers them.
+// It returns an error if a schedule targets an unknown docs type, an unsupported mode or has an invalid cron expression.
+func NewScheduler(config configuration.SchedulerConfig, indexer api.Indexer, leadership Leadership) (*Scheduler, error) {
+	s := &Scheduler{
+		cron:       cron.New(),
+		indexer:    indexer,
+		leadership: leadership,
+	}
+	s.enabled.Store(config.Enabled)
+
+	for _, schedule := range config.Schedules {
+		if !api.SupportsMode(schedule.DocsType, schedule.Mode) {
+			return nil, fmt.Errorf("invalid schedule %s/%s: unsupported docs type or mode", schedule.DocsType, schedule.Mode)
+		}
+		run := &scheduledRun{config: schedule}
+		entryID, err := s.cron.AddFunc(schedule.Cron, func() { s.trigger(run) })
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %s/%s: %w", schedule.DocsType, schedule.Mode, err)
+		}
+		run.entryID = entryID
+		s.runs = append(s.runs, run)
+	}
+	return s, nil
+}
+
+// Start starts triggering the scheduled runs in the background.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops triggering new runs. The returned context is done once the active runs have finished.
+func (s *Scheduler) Stop() context.Context {
+	return s.cron.Stop()
+}
+
+// SetEnabled enables or disables the triggering of scheduled runs.
+// Runs already executing are not interrupted.
+func (s *Scheduler) SetEnabled(enabled bool) {
+	s.enabled.Store(enabled)
+	log.Info().Bool("enabled", enabled).Msg("indexation scheduler updated")
+}
+
+// Schedule returns the state of the scheduler and the next run times of the configured runs.
+func (s *Scheduler) Schedule() model.Schedule {
+	schedule := model.Schedule{
+		Enabled: s.enabled.Load(),
+		Leader:  s.isLeader(),
+		Runs:    make([]model.ScheduledRun, 0, len(s.runs)),
+	}
+	for _, run := range s.runs {
+		entry := s.cron.Entry(run.entryID)
+		scheduled := model.ScheduledRun{
+			DocsType: run.config.DocsType,
+			Mode:     run.config.Mode,
+			Cron:     run.config.Cron,
+			Running:  run.running.Load(),
+			NextRun:  entry.Next,
+		}
+		if entry.Next.IsZero() {
+			// The cron is not started yet
+			scheduled.NextRun = entry.Schedule.Next(time.Now())
+		}
+		if !entry.Prev.IsZero() {
+			previous := entry.Prev
+			scheduled.PreviousRun = &previous
+		}
+		schedule.Runs = append(schedule.Runs, scheduled)
+	}
+	return schedule
+}
+
+// trigger executes a scheduled run as an indexation job and waits for it, unless the scheduler is disabled,
+// this instance is not the leader or the previous run is still active.
+func (s *Scheduler) trigger(run *scheduledRun) {
+	logger := log.With().Str("docsType", run.config.DocsType).Str("mode", run.config.Mode).Logger()
+	if !s.enabled.Load() {
+		logger.Debug().Msg("scheduler disabled, scheduled indexation skipped")
+		return
+	}
+	if !s.isLeader() {
+		logger.Debug().Msg("not the leader, scheduled indexation skipped")
+		return
+	}
+	if !run.running.CompareAndSwap(false, true) {
+		logger.Warn().Msg("previous run still active, scheduled indexation skipped")
+		return
+	}
+	defer run.running.Store(false)
+
+	startedAt := time.Now()
+	ctx := logger.WithContext(context.Background())
+	logger.Info().Msg("scheduled indexation started")
+
+	since, err := s.since(ctx, run)
+	if err != nil {
+		logger.Error().Err(err).Msg("could not find the lower bound of the delta, scheduled indexation skipped")
+		return
+	}
+
+	job, err := s.indexer.Start(ctx, api.JobRequest{DocsType: run.config.DocsType, Mode: run.config.Mode, Trigger: model.TriggerScheduler, Since: since})
+	if errors.Is(err, api.ErrJobInProgress) {
+		logger.Warn().Str("job", job.ID).Msg("indexation already in progress, scheduled indexation skipped")
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("could not start scheduled indexation")
+		return
+	}
+	if job, err = s.indexer.Wait(ctx, job.ID); err != nil || job.Status != model.JobSucceeded {
+		logger.Error().Err(err).Str("job", job.ID).Str("status", job.Status).Msg("scheduled indexation failed")
+		return
+	}
+
+	run.mu.Lock()
+	run.lastSuccess = startedAt
+	run.mu.Unlock()
+	logger.Info().Dur("duration", time.Since(startedAt)).Msg("scheduled indexation finished")
+}
+
+// since returns the lower bound of a delta run: the start time of the last successful full or delta run
+// of its docs type, from any replica, as recorded in the indexation history. Without history, it falls back
+// to the last successful run of the schedule on this instance, the first delta run after startup then having
+// no lower bound and rewriting every document.
+func (s *Scheduler) since(ctx context.Context, run *scheduledRun) (time.Time, error) {
+	if run.config.Mode != api.ModeDelta {
+		return time.Time{}, nil
+	}
+	run.mu.Lock()
+	since := run.lastSuccess
+	run.mu.Unlock()
+
+	for _, mode := range []string{api.ModeFull, api.ModeDelta} {
+		page, err := s.indexer.History(ctx, model.JobFilter{DocsType: run.config.DocsType, Mode: mode, Status: model.JobSucceeded, Limit: 1})
+		if errors.Is(err, api.ErrNoHistory) {
+			return since, nil
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if len(page.Jobs) > 0 && page.Jobs[0].StartedAt.After(since) {
+			since = page.Jobs[0].StartedAt
+		}
+	}
+	return since, nil
+}
+
+// isLeader reports whether this instance may trigger the scheduled runs.
+func (s *Scheduler) isLeader() bool {
+	return s.leadership == nil || s.leadership.IsLeader()
+}
@@ -0,0 +1,100 @@
+package bootstrap
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/khedhrije/podcaster-indexer-api/internal/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// fakeIndexer is an api.Indexer recording the started jobs, which succeed at once, and querying the given history.
+type fakeIndexer struct {
+	api.Indexer
+	history  port.History
+	requests []api.JobRequest
+}
+
+func (f *fakeIndexer) Start(ctx context.Context, request api.JobRequest) (model.Job, error) {
+	f.requests = append(f.requests, request)
+	return model.Job{ID: "job", Status: model.JobSucceeded}, nil
+}
+
+func (f *fakeIndexer) Wait(ctx context.Context, id string) (model.Job, error) {
+	return model.Job{ID: id, Status: model.JobSucceeded}, nil
+}
+
+func (f *fakeIndexer) History(ctx context.Context, filter model.JobFilter) (model.JobPage, error) {
+	return f.history.Find(ctx, filter)
+}
+
+func Test_NewScheduler_WhenSchedulesAreValid(t *testing.T) {
+	scheduler, err := NewScheduler(configuration.SchedulerConfig{
+		Enabled: true,
+		Schedules: []configuration.ScheduleConfig{
+			{DocsType: "programs", Mode: "full", Cron: "0 3 * * *"},
+			{DocsType: "episodes", Mode: "delta", Cron: "*/15 * * * *"},
+		},
+	}, nil, nil)
+
+	assert.NoError(t, err)
+	schedule := scheduler.Schedule()
+	assert.True(t, schedule.Enabled)
+	assert.Len(t, schedule.Runs, 2)
+	assert.False(t, schedule.Runs[0].NextRun.IsZero(), "Next run should be computed before the scheduler starts")
+}
+
+func Test_NewScheduler_WhenSchedulesAreInvalid(t *testing.T) {
+	for name, schedule := range map[string]configuration.ScheduleConfig{
+		"unknown docs type":  {DocsType: "podcasts", Mode: "full", Cron: "0 3 * * *"},
+		"unknown mode":       {DocsType: "programs", Mode: "partial", Cron: "0 3 * * *"},
+		"unsupported delta":  {DocsType: "medias", Mode: "delta", Cron: "0 3 * * *"},
+		"invalid expression": {DocsType: "programs", Mode: "full", Cron: "every night"},
+	} {
+		_, err := NewScheduler(configuration.SchedulerConfig{Schedules: []configuration.ScheduleConfig{schedule}}, nil, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func Test_SetEnabled(t *testing.T) {
+	scheduler, err := NewScheduler(configuration.SchedulerConfig{Enabled: true}, nil, nil)
+	assert.NoError(t, err)
+
+	scheduler.SetEnabled(false)
+
+	assert.False(t, scheduler.Schedule().Enabled)
+}
+
+func Test_Schedule_WhenNotLeader(t *testing.T) {
+	scheduler, err := NewScheduler(configuration.SchedulerConfig{Enabled: true}, nil, &LeaderElector{})
+
+	assert.NoError(t, err)
+	assert.False(t, scheduler.Schedule().Leader, "Should not be leader before the lease is acquired")
+}
+
+func Test_Trigger_WhenDeltaFollowsRunsOfHistory(t *testing.T) {
+	ctx := context.Background()
+	fullStart := time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)
+	history := memory.NewHistoryAdapter()
+	for _, job := range []model.Job{
+		{ID: "full", DocsType: api.Episodes, Mode: api.ModeFull, Status: model.JobSucceeded, StartedAt: fullStart},
+		{ID: "failed", DocsType: api.Episodes, Mode: api.ModeDelta, Status: model.JobFailed, StartedAt: fullStart.Add(time.Hour)},
+		{ID: "other", DocsType: api.Programs, Mode: api.ModeDelta, Status: model.JobSucceeded, StartedAt: fullStart.Add(time.Hour)},
+	} {
+		assert.NoError(t, history.Save(ctx, job))
+	}
+	indexer := &fakeIndexer{history: history}
+	scheduler, err := NewScheduler(configuration.SchedulerConfig{Enabled: true, Schedules: []configuration.ScheduleConfig{
+		{DocsType: api.Episodes, Mode: api.ModeDelta, Cron: "*/15 * * * *"},
+	}}, indexer, nil)
+	assert.NoError(t, err)
+
+	scheduler.trigger(scheduler.runs[0])
+
+	assert.Len(t, indexer.requests, 1)
+	assert.Equal(t, fullStart, indexer.requests[0].Since, "Delta should start from the last successful run of its docs type")
+}
@@ -4,7 +4,11 @@
 package configuration
 
 import (
+	"errors"
+	"fmt"
 	"github.com/spf13/viper"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,9 +17,21 @@ import (
 var Config *AppConfig
 
 // init initializes the configuration by loading settings from the environment.
-// It calls loadFromEnv to populate the Config variable with the application settings.
+// It calls loadFromEnv to populate the Config variable with the application settings, and fails on invalid ones.
 func init() {
 	Config = loadFromEnv()
+	if err := Config.Validate(); err != nil {
+		panic(fmt.Sprintf("invalid configuration: %v", err))
+	}
+}
+
+// Validate checks the settings that would otherwise fail at runtime.
+func (config *AppConfig) Validate() error {
+	leasing := config.Scheduler.LeaderElection.Enabled || config.Indexation.JobLease
+	if leasing && config.Scheduler.LeaderElection.TTL <= 0 {
+		return errors.New("INDEXATION_LEADER_LEASE_TTL must be positive when leader election or job leases are enabled")
+	}
+	return nil
 }
 
 // AppConfig defines the structure of the application's configuration settings.
@@ -30,6 +46,13 @@ type AppConfig struct {
 	CacheConfig    CacheConfig    // Configuration settings for caching
 	Elasticsearch  SearchEngineConfig
 	AccountApi     AccountApi
+	Scheduler      SchedulerConfig  // Configuration settings for periodic reindexing
+	Indexation     IndexationConfig // Configuration settings for indexation jobs
+	Webhooks       WebhooksConfig   // Configuration settings for the outbound webhooks
+	Nats           NatsConfig       // Configuration settings for the NATS message broker
+	Outbox         OutboxConfig     // Configuration settings for the polling of the MySQL outbox
+	Binlog         BinlogConfig     // Configuration settings for the change data capture from the MySQL binlog
+	Search         SearchConfig     // Configuration settings for the search across the docs types
 }
 
 // DatabaseConfig defines the configuration settings for the database connection.
@@ -53,17 +76,142 @@ type SearchEngineConfig struct {
 	URL      string
 	User     string
 	Password string
+	// SynonymsFile is the local path of the synonyms file read by the search analyzers, on a volume shared with
+	// the Elasticsearch nodes. When empty, the synonyms are inline and cannot be applied to the live indexes.
+	SynonymsFile string
+	// SynonymsPath is the path of the same file relative to the configuration directory of Elasticsearch.
+	SynonymsPath string
 }
 
 type AccountApi struct {
 	BaseURL string
 }
 
+// IndexationConfig defines the configuration settings of the indexation jobs.
+type IndexationConfig struct {
+	DefaultTimeout time.Duration            // Deadline of the jobs of docs types without a specific one, none if zero, the default
+	Timeouts       map[string]time.Duration // Deadline of the jobs per docs type, sized to their largest rebuild
+	Severities     map[string]string        // Severity of the data-quality rules, overriding the built-in one, per rule name
+	Enrichers      map[string][]string      // Built-in enrichers run in order per docs type, with their error handling
+	SummaryLength  int                      // Maximum length of the summaries of the descriptions, none if zero
+	KeepRawText    bool                     // Whether the raw descriptions are kept along with the normalized ones
+	Languages      map[string][]string      // Languages of the localized fields per docs type, e.g. LocalizedName.fr
+	DetectLanguage bool                     // Whether the language of each document is detected to localize it in that language only
+	JobLease       bool                     // Whether a job holds a lease of its docs type, so that one job runs per docs type across the replicas
+}
+
+// SearchConfig defines the federated search across the docs types.
+type SearchConfig struct {
+	GroupSize int                // Number of hits per docs type when none is requested
+	TopHits   int                // Number of hits of the blended top hits
+	Boosts    map[string]float64 // Multiplier of the scores of each docs type in the top hits, 1 if missing
+}
+
+// SchedulerConfig defines the periodic reindexing performed by the built-in scheduler.
+type SchedulerConfig struct {
+	Enabled        bool                 // Whether scheduled runs are triggered at startup
+	Schedules      []ScheduleConfig     // Scheduled runs, one per docs type and mode
+	LeaderElection LeaderElectionConfig // Leader election among replicas for scheduled runs
+}
+
+// LeaderElectionConfig defines the lease used to elect the replica triggering scheduled runs and capturing the binlog.
+type LeaderElectionConfig struct {
+	Enabled   bool          // Whether only the leader replica triggers scheduled runs and captures the binlog
+	LeaseName string        // Name of the lease row shared by the replicas
+	Holder    string        // Identity of this replica in the leader and job leases, defaults to the hostname
+	TTL       time.Duration // Duration after which a leader or job lease that is not renewed expires
+}
+
+// ScheduleConfig defines a single scheduled run.
+type ScheduleConfig struct {
+	DocsType string // Docs type to index (e.g., programs, episodes)
+	Mode     string // Indexation mode (full, delta or reconcile)
+	Cron     string // Cron expression triggering the run
+}
+
+// OutboxConfig defines the polling of the index_outbox table filled by the writing services.
+type OutboxConfig struct {
+	Enabled     bool          // Whether the outbox is polled
+	Interval    time.Duration // Delay between two polls once the outbox is drained
+	BatchSize   int           // Number of entries processed per transaction
+	MaxAttempts int           // Number of failed attempts after which an entry is no longer processed
+}
+
+// BinlogConfig defines the change data capture from the MySQL binlog. It requires row-based replication
+// with full row images and metadata (binlog_format=ROW, binlog_row_image=FULL and binlog_row_metadata=FULL).
+// With leader election, only the leader replica captures the binlog, so a single server identifier is shared by the replicas.
+type BinlogConfig struct {
+	Enabled    bool   // Whether the binlog is tailed
+	ServerID   uint32 // Replica server identifier, unique among the replicas of the MySQL server
+	Checkpoint string // Name of the checkpoint recording the position reached in the binlog
+}
+
+// NatsConfig defines the connection to the NATS message broker.
+type NatsConfig struct {
+	URL           string         // URL of the NATS server, the broker is not used if empty
+	SubjectPrefix string         // Prefix of the subjects the events are published on
+	Consumer      ConsumerConfig // Consumption of the indexation commands
+}
+
+// ConsumerConfig defines the consumption of the indexation commands from a JetStream stream.
+type ConsumerConfig struct {
+	Enabled           bool          // Whether the commands are consumed, off by default so that a publisher-only broker takes no commands
+	Stream            string        // Stream holding the commands, created if missing
+	Subject           string        // Subjects the commands are published on
+	Durable           string        // Name of the durable consumer shared by the replicas
+	DeadLetterSubject string        // Subject the commands that cannot be processed are moved to
+	MaxDeliver        int           // Number of deliveries of a failing command before it is dead-lettered
+	AckWait           time.Duration // Deadline of the processing of a command before it is redelivered
+	RetryDelay        time.Duration // Delay before the redelivery of a failed command
+}
+
+// WebhooksConfig defines the outbound webhooks notified of the outcome of the indexation jobs.
+type WebhooksConfig struct {
+	Endpoints  []WebhookConfig // Webhooks to notify
+	Secret     string          // Key signing the payloads with HMAC-SHA256
+	Timeout    time.Duration   // Deadline of a single delivery attempt
+	MaxRetries int             // Number of retries of a failed delivery
+	RetryDelay time.Duration   // Delay between two delivery attempts
+}
+
+// WebhookConfig defines a single outbound webhook.
+type WebhookConfig struct {
+	URL    string   // URL receiving the notifications
+	Events []string // Notification types sent to the webhook, all of them if empty
+}
+
 // loadFromEnv loads configuration settings from environment variables and returns an AppConfig instance.
 // It uses viper to handle the environment variables and sets default values if specific configurations are not provided.
 func loadFromEnv() *AppConfig {
 	viper.AutomaticEnv() // Automatically read environment variables
 	viper.SetDefault("APP_PODCASTER_INDEXER_API_HOST_PORT", 8080)
+	viper.SetDefault("ES_SYNONYMS_PATH", "analysis/podcaster-synonyms.txt")
+	viper.SetDefault("INDEXATION_ENRICHERS", "cats:normalize,languages,slug;tags:normalize,languages,slug;walls:normalize,languages,slug;"+
+		"blocks:normalize,languages,slug;programs:normalize,languages,slug,episode-count,word-count;episodes:normalize,languages,slug,word-count")
+	viper.SetDefault("INDEXATION_LANGUAGES", "cats:fr,en;tags:fr,en;walls:fr,en;blocks:fr,en;programs:fr,en;episodes:fr,en")
+	viper.SetDefault("INDEXATION_SUMMARY_LENGTH", 200)
+	viper.SetDefault("SEARCH_GROUP_SIZE", 5)
+	viper.SetDefault("SEARCH_TOP_HITS", 10)
+	viper.SetDefault("SEARCH_BOOSTS", "programs:2;episodes:1.5")
+	viper.SetDefault("INDEXATION_SCHEDULER_ENABLED", true)
+	viper.SetDefault("INDEXATION_LEADER_LEASE_NAME", "indexation-scheduler")
+	viper.SetDefault("INDEXATION_LEADER_LEASE_TTL", 30*time.Second)
+	viper.SetDefault("INDEXATION_WEBHOOK_TIMEOUT", 10*time.Second)
+	viper.SetDefault("INDEXATION_OUTBOX_INTERVAL", 5*time.Second)
+	viper.SetDefault("INDEXATION_OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("INDEXATION_OUTBOX_MAX_ATTEMPTS", 10)
+	viper.SetDefault("MYSQL_BINLOG_SERVER_ID", 4201)
+	viper.SetDefault("MYSQL_BINLOG_CHECKPOINT", "podcaster-indexer-api")
+	viper.SetDefault("NATS_SUBJECT_PREFIX", "indexer")
+	viper.SetDefault("NATS_COMMANDS_STREAM", "INDEXER_COMMANDS")
+	viper.SetDefault("NATS_COMMANDS_SUBJECT", "indexer.commands.>")
+	viper.SetDefault("NATS_CONSUMER_DURABLE", "podcaster-indexer-api")
+	viper.SetDefault("NATS_DEAD_LETTER_SUBJECT", "indexer.dead-letter.commands")
+	viper.SetDefault("NATS_MAX_DELIVER", 5)
+	viper.SetDefault("NATS_ACK_WAIT", 30*time.Second)
+	viper.SetDefault("NATS_RETRY_DELAY", 10*time.Second)
+	viper.SetDefault("INDEXATION_WEBHOOK_MAX_RETRIES", 3)
+	viper.SetDefault("INDEXATION_WEBHOOK_RETRY_DELAY", 5*time.Second)
 	return &AppConfig{
 		Name:        viper.GetString("APP_PODCASTER_INDEXER_API_NAME"),              // Application name
 		Env:         viper.GetString("APP_PODCASTER_INDEXER_API_ENV"),               // Application environment
@@ -85,9 +233,181 @@ func loadFromEnv() *AppConfig {
 			DSN: viper.GetString("REDIS_URL"), // Data source name for Redis
 		},
 		Elasticsearch: SearchEngineConfig{
-			URL:      viper.GetString("ES_URL"),
-			User:     viper.GetString("ES_USER"),
-			Password: viper.GetString("ES_PASSWORD"),
+			URL:          viper.GetString("ES_URL"),
+			User:         viper.GetString("ES_USER"),
+			Password:     viper.GetString("ES_PASSWORD"),
+			SynonymsFile: viper.GetString("ES_SYNONYMS_FILE"),
+			SynonymsPath: viper.GetString("ES_SYNONYMS_PATH"),
 		},
+		Indexation: IndexationConfig{
+			DefaultTimeout: viper.GetDuration("INDEXATION_DEFAULT_TIMEOUT"),
+			Timeouts:       parseTimeouts(viper.GetString("INDEXATION_TIMEOUTS")),
+			Severities:     parseSeverities(viper.GetString("INDEXATION_QUALITY_SEVERITIES")),
+			Enrichers:      parseLists(viper.GetString("INDEXATION_ENRICHERS")),
+			SummaryLength:  viper.GetInt("INDEXATION_SUMMARY_LENGTH"),
+			KeepRawText:    viper.GetBool("INDEXATION_KEEP_RAW_DESCRIPTION"),
+			Languages:      parseLists(viper.GetString("INDEXATION_LANGUAGES")),
+			DetectLanguage: viper.GetBool("INDEXATION_DETECT_LANGUAGE"),
+			JobLease:       viper.GetBool("INDEXATION_JOB_LEASE_ENABLED"),
+		},
+		Search: SearchConfig{
+			GroupSize: viper.GetInt("SEARCH_GROUP_SIZE"),
+			TopHits:   viper.GetInt("SEARCH_TOP_HITS"),
+			Boosts:    parseBoosts(viper.GetString("SEARCH_BOOSTS")),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:   viper.GetBool("INDEXATION_SCHEDULER_ENABLED"),
+			Schedules: parseSchedules(viper.GetString("INDEXATION_SCHEDULES")),
+			LeaderElection: LeaderElectionConfig{
+				Enabled:   viper.GetBool("INDEXATION_LEADER_ELECTION_ENABLED"),
+				LeaseName: viper.GetString("INDEXATION_LEADER_LEASE_NAME"),
+				Holder:    viper.GetString("INDEXATION_LEADER_HOLDER"),
+				TTL:       viper.GetDuration("INDEXATION_LEADER_LEASE_TTL"),
+			},
+		},
+		Webhooks: WebhooksConfig{
+			Endpoints:  parseWebhooks(viper.GetString("INDEXATION_WEBHOOKS")),
+			Secret:     viper.GetString("INDEXATION_WEBHOOK_SECRET"),
+			Timeout:    viper.GetDuration("INDEXATION_WEBHOOK_TIMEOUT"),
+			MaxRetries: viper.GetInt("INDEXATION_WEBHOOK_MAX_RETRIES"),
+			RetryDelay: viper.GetDuration("INDEXATION_WEBHOOK_RETRY_DELAY"),
+		},
+		Outbox: OutboxConfig{
+			Enabled:     viper.GetBool("INDEXATION_OUTBOX_ENABLED"),
+			Interval:    viper.GetDuration("INDEXATION_OUTBOX_INTERVAL"),
+			BatchSize:   viper.GetInt("INDEXATION_OUTBOX_BATCH_SIZE"),
+			MaxAttempts: viper.GetInt("INDEXATION_OUTBOX_MAX_ATTEMPTS"),
+		},
+		Binlog: BinlogConfig{
+			Enabled:    viper.GetBool("MYSQL_BINLOG_ENABLED"),
+			ServerID:   viper.GetUint32("MYSQL_BINLOG_SERVER_ID"),
+			Checkpoint: viper.GetString("MYSQL_BINLOG_CHECKPOINT"),
+		},
+		Nats: NatsConfig{
+			URL:           viper.GetString("NATS_URL"),
+			SubjectPrefix: viper.GetString("NATS_SUBJECT_PREFIX"),
+			Consumer: ConsumerConfig{
+				Enabled:           viper.GetBool("NATS_CONSUMER_ENABLED"),
+				Stream:            viper.GetString("NATS_COMMANDS_STREAM"),
+				Subject:           viper.GetString("NATS_COMMANDS_SUBJECT"),
+				Durable:           viper.GetString("NATS_CONSUMER_DURABLE"),
+				DeadLetterSubject: viper.GetString("NATS_DEAD_LETTER_SUBJECT"),
+				MaxDeliver:        viper.GetInt("NATS_MAX_DELIVER"),
+				AckWait:           viper.GetDuration("NATS_ACK_WAIT"),
+				RetryDelay:        viper.GetDuration("NATS_RETRY_DELAY"),
+			},
+		},
+	}
+}
+
+// parseTimeouts parses deadlines written as "docsType:duration" entries separated by semicolons,
+// e.g. "medias:2h;episodes:90m". Entries with an invalid duration are ignored.
+func parseTimeouts(value string) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ";") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			continue
+		}
+		timeouts[strings.TrimSpace(parts[0])] = timeout
+	}
+	return timeouts
+}
+
+// parseBoosts parses score multipliers written as "docsType:boost" entries separated by semicolons,
+// e.g. "programs:2;tags:0.5". Entries with an invalid or negative boost are ignored.
+func parseBoosts(value string) map[string]float64 {
+	boosts := make(map[string]float64)
+	for _, entry := range strings.Split(value, ";") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		boost, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || boost < 0 {
+			continue
+		}
+		boosts[strings.TrimSpace(parts[0])] = boost
+	}
+	return boosts
+}
+
+// parseSeverities parses rule severities written as "rule:severity" entries separated by semicolons,
+// e.g. "episodes.position-unique:fail;medias.direct-link-required:warn". Entries without a severity are ignored.
+func parseSeverities(value string) map[string]string {
+	severities := make(map[string]string)
+	for _, entry := range strings.Split(value, ";") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			continue
+		}
+		severities[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return severities
+}
+
+// parseLists parses lists per docs type written as "docsType:item,item" entries separated by semicolons,
+// e.g. enrichers as "programs:slug,episode-count=fail;episodes:slug". Empty items are ignored.
+func parseLists(value string) map[string][]string {
+	lists := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		docsType := strings.TrimSpace(parts[0])
+		for _, item := range strings.Split(parts[1], ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				lists[docsType] = append(lists[docsType], item)
+			}
+		}
+	}
+	return lists
+}
+
+// parseSchedules parses scheduled runs written as "docsType:mode:cron" entries separated by semicolons,
+// e.g. "programs:full:0 3 * * *;episodes:delta:*/15 * * * *".
+func parseSchedules(value string) []ScheduleConfig {
+	var schedules []ScheduleConfig
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// Malformed entries are kept with empty parts so that they are rejected when the scheduler starts
+		parts := append(strings.SplitN(entry, ":", 3), "", "")
+		schedules = append(schedules, ScheduleConfig{
+			DocsType: strings.TrimSpace(parts[0]),
+			Mode:     strings.TrimSpace(parts[1]),
+			Cron:     strings.TrimSpace(parts[2]),
+		})
+	}
+	return schedules
+}
+
+// parseWebhooks parses webhooks written as "url|type,type" entries separated by semicolons, the types being optional,
+// e.g. "https://cache.internal/hooks|index.promoted;https://mobile.internal/hooks".
+func parseWebhooks(value string) []WebhookConfig {
+	var webhooks []WebhookConfig
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "|", 2)
+		webhook := WebhookConfig{URL: strings.TrimSpace(parts[0])}
+		if len(parts) == 2 {
+			for _, event := range strings.Split(parts[1], ",") {
+				if event = strings.TrimSpace(event); event != "" {
+					webhook.Events = append(webhook.Events, event)
+				}
+			}
+		}
+		webhooks = append(webhooks, webhook)
 	}
+	return webhooks
 }
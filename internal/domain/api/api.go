@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"time"
 )
 
 // Indexer interface defines a method for handling categories
@@ -15,18 +17,54 @@ type Indexer interface {
 	Episodes(ctx context.Context) error
 	Medias(ctx context.Context) error
 	All(ctx context.Context) error
+	DryRun(ctx context.Context, docsType string) (DryRunReport, error)
+	Reindex(ctx context.Context, docsType string) error
+	Delta(ctx context.Context, docsType string, since time.Time) error
+	Reconcile(ctx context.Context, docsType string) error
+	Start(ctx context.Context, request JobRequest) (model.Job, error)
+	Job(id string) (model.Job, error)
+	Jobs() []model.Job
+	Cancel(id string) (model.Job, error)
+	Wait(ctx context.Context, id string) (model.Job, error)
+	Subscribe(id string) (<-chan model.JobEvent, func(), error)
+	History(ctx context.Context, filter model.JobFilter) (model.JobPage, error)
+	IndexDocument(ctx context.Context, docsType, id string) error
+	DeleteDocument(ctx context.Context, docsType, id string) error
+	Handle(ctx context.Context, command model.Command) error
+	Apply(ctx context.Context, change model.Change) error
+	Verify(ctx context.Context, docsType string, options VerifyOptions) (model.ConsistencyReport, error)
+	Diff(ctx context.Context, docsType, from, to string, sample int) (model.GenerationDiff, error)
+	Integrity(ctx context.Context) (model.IntegrityReport, error)
+	SearchTerms(ctx context.Context) (model.SearchTerms, error)
+	UpdateSearchTerms(ctx context.Context, terms model.SearchTerms, reload bool) (model.SearchTermsUpdate, error)
 }
 
 // indexerApi struct implements the Indexer interface
 type indexerApi struct {
-	indexer        port.Indexer
-	wallAdapter    port.Wall
-	catAdapter     port.Category
-	tagAdapter     port.Tag
-	blockAdapter   port.Block
-	programAdapter port.Program
-	episodeAdapter port.Episode
-	mediaAdapter   port.Media
+	indexer            port.Indexer
+	wallAdapter        port.Wall
+	catAdapter         port.Category
+	tagAdapter         port.Tag
+	blockAdapter       port.Block
+	programAdapter     port.Program
+	episodeAdapter     port.Episode
+	mediaAdapter       port.Media
+	jobs               *jobRegistry
+	jobLease           port.Lease
+	leaseHolder        string
+	leaseTTL           time.Duration
+	defaultTimeout     time.Duration
+	timeouts           map[string]time.Duration
+	severities         map[string]string
+	enrichers          map[string][]enrichment
+	summaryLength      int
+	keepRawDescription bool
+	languages          map[string][]string
+	detectLanguage     bool
+	history            port.History
+	searchTerms        port.SearchTerms
+	notifiers          []port.Notifier
+	publishers         []port.Publisher
 }
 
 // NewIndexerApi returns a new instance of indexerApi
@@ -38,8 +76,9 @@ func NewIndexerApi(
 	blockAdapter port.Block,
 	programAdapter port.Program,
 	episodeAdapter port.Episode,
-	mediaAdapter port.Media) Indexer {
-	return &indexerApi{
+	mediaAdapter port.Media,
+	options ...Option) Indexer {
+	api := &indexerApi{
 		indexer:        indexer,
 		catAdapter:     catAdapter,
 		tagAdapter:     tagAdapter,
@@ -48,7 +87,13 @@ func NewIndexerApi(
 		programAdapter: programAdapter,
 		episodeAdapter: episodeAdapter,
 		mediaAdapter:   mediaAdapter,
+		jobs:           newJobRegistry(),
+		enrichers:      make(map[string][]enrichment),
 	}
+	for _, option := range options {
+		option(api)
+	}
+	return api
 }
 
 // mapArrayToInterface converts an array of any type to an array of empty interfaces
@@ -73,3 +118,6 @@ const (
 	Episodes = "episodes"
 	Medias   = "medias"
 )
+
+// DocsTypes lists every docs type handled by the indexer.
+var DocsTypes = []string{Walls, Blocks, Programs, Episodes, Medias, Cats, Tags}
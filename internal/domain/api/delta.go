@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"time"
+)
+
+// Indexation modes.
+const (
+	// ModeFull rebuilds a docs type into a new index and promotes it to the latest alias.
+	ModeFull = "full"
+	// ModeDelta writes the documents updated since the previous run into the latest index
+	// and removes the documents of the entities soft-deleted since then.
+	ModeDelta = "delta"
+	// ModeReconcile removes from the latest index the documents of the entities that no longer exist.
+	ModeReconcile = "reconcile"
+)
+
+var (
+	// ErrDeltaNotSupported is returned when the docs type cannot be indexed incrementally.
+	ErrDeltaNotSupported = errors.New("delta indexation is not supported for this docs type")
+	// ErrNoLatestIndex is returned when a delta indexation runs before any full indexation.
+	ErrNoLatestIndex = errors.New("no latest index, a full indexation is required first")
+)
+
+// Delta indexes the documents of the docs type created or updated since the given time
+// directly into the index currently holding the latest alias, then removes from it the documents
+// of the entities soft-deleted since the given time. The programs of the changed episodes are reindexed
+// when their number of episodes is enriched.
+func (api indexerApi) Delta(ctx context.Context, docsType string, since time.Time) error {
+	latestIndexName := api.aliasedIndex(ctx, latestAlias, docsType)
+	if latestIndexName == "" {
+		return ErrNoLatestIndex
+	}
+	reportIndexName(ctx, latestIndexName)
+
+	// Retrieve updated documents
+	reportPhase(ctx, model.PhaseLoad)
+	items, err := api.loadUpdatedSince(ctx, docsType, since)
+	if err != nil {
+		return fmt.Errorf("could not find updated %s: %w", docsType, err)
+	}
+	reportProgress(ctx, func(progress *model.Progress) {
+		progress.RowsRead = len(items)
+	})
+
+	// Enrich documents and check them against the data-quality rules
+	reportPhase(ctx, model.PhaseValidate)
+	items, _, err = api.prepare(ctx, docsType, items)
+	if err != nil {
+		return err
+	}
+	reportProgress(ctx, func(progress *model.Progress) {
+		progress.Documents = len(items)
+	})
+
+	// Find the entities soft-deleted since then
+	deletedIDs, err := api.loadDeletedSince(ctx, docsType, since)
+	if err != nil {
+		return fmt.Errorf("could not find deleted %s: %w", docsType, err)
+	}
+
+	// Find the programs of the changed episodes, before and after the changes
+	changedIDs := append([]string(nil), deletedIDs...)
+	for _, item := range items {
+		changedIDs = append(changedIDs, documentID(item))
+	}
+	programIDs := api.indexedPrograms(ctx, latestIndexName, docsType, changedIDs...)
+	for _, item := range items {
+		programIDs = append(programIDs, episodeProgram(item))
+	}
+
+	// Bulk index documents, overwriting the existing ones
+	reportPhase(ctx, model.PhaseIndex)
+	if err := api.record(ctx, latestIndexName, items); err != nil {
+		return fmt.Errorf("could not record bulk updated %s: %w", docsType, err)
+	}
+
+	// Remove the documents of the soft-deleted entities, then reindex the programs of the changed episodes
+	if err := api.remove(ctx, latestIndexName, deletedIDs); err != nil {
+		return err
+	}
+	return api.refreshPrograms(ctx, programIDs...)
+}
+
+// loadUpdatedSince retrieves the documents of the given docs type created or updated since the given time.
+func (api indexerApi) loadUpdatedSince(ctx context.Context, docsType string, since time.Time) ([]interface{}, error) {
+	switch docsType {
+	case Cats:
+		categories, err := api.catAdapter.FindUpdatedSince(ctx, since)
+		return mapArrayToInterface(categories), err
+	case Tags:
+		tags, err := api.tagAdapter.FindUpdatedSince(ctx, since)
+		return mapArrayToInterface(tags), err
+	case Walls:
+		walls, err := api.wallAdapter.FindUpdatedSince(ctx, since)
+		return mapArrayToInterface(walls), err
+	case Blocks:
+		blocks, err := api.blockAdapter.FindUpdatedSince(ctx, since)
+		return mapArrayToInterface(blocks), err
+	case Programs:
+		programs, err := api.programAdapter.FindUpdatedSince(ctx, since)
+		return mapArrayToInterface(programs), err
+	case Episodes:
+		episodes, err := api.episodeAdapter.FindUpdatedSince(ctx, since)
+		return mapArrayToInterface(episodes), err
+	case Medias:
+		return nil, ErrDeltaNotSupported
+	default:
+		return nil, ErrUnknownDocsType
+	}
+}
+
+// loadDeletedSince retrieves the identifiers of the entities of the given docs type soft-deleted since the given time.
+// Only programs and episodes are soft-deleted.
+func (api indexerApi) loadDeletedSince(ctx context.Context, docsType string, since time.Time) ([]string, error) {
+	switch docsType {
+	case Programs:
+		return api.programAdapter.FindDeletedSince(ctx, since)
+	case Episodes:
+		return api.episodeAdapter.FindDeletedSince(ctx, since)
+	default:
+		return nil, nil
+	}
+}
+
+// SupportsMode reports whether the docs type can be indexed with the given mode.
+func SupportsMode(docsType, mode string) bool {
+	switch mode {
+	case ModeFull:
+		return isDocsType(docsType)
+	case ModeDelta:
+		return isDocsType(docsType) && docsType != Medias
+	case ModeReconcile:
+		return docsType == Programs || docsType == Episodes
+	default:
+		return false
+	}
+}
+
+// isDocsType reports whether the docs type is handled by the indexer.
+func isDocsType(docsType string) bool {
+	for _, known := range DocsTypes {
+		if known == docsType {
+			return true
+		}
+	}
+	return false
+}
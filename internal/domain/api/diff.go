@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"reflect"
+	"sort"
+)
+
+// Diff sample sizes.
+const (
+	DefaultDiffSample = 10  // Number of modified documents detailed when none is requested
+	MaxDiffSample     = 100 // Maximum number of modified documents detailed
+)
+
+var (
+	// ErrUnknownGeneration is returned when a diff designates a generation by an alias the indexer does not manage.
+	ErrUnknownGeneration = errors.New("unknown generation, expected latest, previous or in-progress")
+	// ErrNoGeneration is returned when no index holds the alias of a generation.
+	ErrNoGeneration = errors.New("no index holds this generation")
+)
+
+// Diff compares two generations of the index of the docs type, designated by their alias, by document identifier
+// and content hash. It reports the added, removed and modified documents, and details the changed fields
+// of the first modified documents, up to sample of them. The sample defaults to DefaultDiffSample and is capped to MaxDiffSample.
+func (api indexerApi) Diff(ctx context.Context, docsType, from, to string, sample int) (model.GenerationDiff, error) {
+	if !isDocsType(docsType) {
+		return model.GenerationDiff{}, ErrUnknownDocsType
+	}
+	if sample <= 0 {
+		sample = DefaultDiffSample
+	}
+	sample = min(sample, MaxDiffSample)
+	fromGeneration, fromSources, err := api.generation(ctx, docsType, from)
+	if err != nil {
+		return model.GenerationDiff{}, err
+	}
+	toGeneration, toSources, err := api.generation(ctx, docsType, to)
+	if err != nil {
+		return model.GenerationDiff{}, err
+	}
+
+	diff := model.GenerationDiff{
+		DocsType: docsType,
+		From:     fromGeneration,
+		To:       toGeneration,
+		Added:    []string{},
+		Removed:  []string{},
+		Modified: []string{},
+		Samples:  []model.DocumentDiff{},
+	}
+	for id, toSource := range toSources {
+		fromSource, ok := fromSources[id]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, id)
+		case sameSource(fromSource, toSource):
+			diff.Unchanged++
+		default:
+			diff.Modified = append(diff.Modified, id)
+		}
+	}
+	for id := range fromSources {
+		if _, ok := toSources[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+
+	for _, id := range diff.Modified[:min(sample, len(diff.Modified))] {
+		fields, err := fieldDiffs(fromSources[id], toSources[id])
+		if err != nil {
+			return model.GenerationDiff{}, fmt.Errorf("could not compare document %s: %w", id, err)
+		}
+		diff.Samples = append(diff.Samples, model.DocumentDiff{ID: id, Fields: fields})
+	}
+	return diff, nil
+}
+
+// generation returns the generation of the index of the docs type designated by the alias, along with its documents.
+func (api indexerApi) generation(ctx context.Context, docsType, alias string) (model.IndexGeneration, map[string][]byte, error) {
+	if alias != latestAlias && alias != previousAlias && alias != inProgressAlias {
+		return model.IndexGeneration{}, nil, ErrUnknownGeneration
+	}
+	indexName := api.aliasedIndex(ctx, alias, docsType)
+	if indexName == "" {
+		return model.IndexGeneration{}, nil, fmt.Errorf("%w: %s", ErrNoGeneration, alias)
+	}
+	sources, err := api.indexer.DocumentSources(ctx, indexName)
+	if err != nil {
+		return model.IndexGeneration{}, nil, fmt.Errorf("could not list the documents of %s: %w", indexName, err)
+	}
+	return model.IndexGeneration{Alias: alias, IndexName: indexName, Documents: len(sources)}, sources, nil
+}
+
+// sameSource reports whether two document sources have the same content hash.
+func sameSource(from, to []byte) bool {
+	fromHash, err := contentHash(from)
+	if err != nil {
+		return false
+	}
+	toHash, err := contentHash(to)
+	return err == nil && fromHash == toHash
+}
+
+// fieldDiffs returns the fields whose value differs between two document sources, sorted by path.
+func fieldDiffs(from, to []byte) ([]model.FieldDiff, error) {
+	var fromDocument, toDocument map[string]interface{}
+	if err := json.Unmarshal(from, &fromDocument); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &toDocument); err != nil {
+		return nil, err
+	}
+	fields := compareObjects("", fromDocument, toDocument)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+	return fields, nil
+}
+
+// compareObjects returns the fields whose value differs between two decoded JSON objects, prefixing their path.
+// Nested objects are compared field by field, any other value as a whole.
+func compareObjects(prefix string, from, to map[string]interface{}) []model.FieldDiff {
+	var fields []model.FieldDiff
+	for field, toValue := range to {
+		fields = append(fields, compareValues(prefix+field, from[field], toValue)...)
+	}
+	for field, fromValue := range from {
+		if _, ok := to[field]; !ok {
+			fields = append(fields, compareValues(prefix+field, fromValue, nil)...)
+		}
+	}
+	return fields
+}
+
+// compareValues returns the differences between two decoded JSON values of the field.
+func compareValues(field string, from, to interface{}) []model.FieldDiff {
+	fromObject, fromIsObject := from.(map[string]interface{})
+	toObject, toIsObject := to.(map[string]interface{})
+	if fromIsObject && toIsObject {
+		return compareObjects(field+".", fromObject, toObject)
+	}
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	return []model.FieldDiff{{Field: field, From: from, To: to}}
+}
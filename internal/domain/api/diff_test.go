@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Diff_WhenGenerationsDiffer(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1", Name: "Program"}, &model.Program{ID: "p2", Name: "Renamed"}, &model.Program{ID: "p4"})
+	ctx := context.Background()
+	assert.NoError(t, indexer.CreateIndex(ctx, "programs-0", Programs, model.SearchTerms{}))
+	assert.NoError(t, indexer.CreateAlias(ctx, "programs-0", previousAlias))
+	_, err := indexer.RecordBulkItems(ctx, "programs-0", []interface{}{&model.Program{ID: "p1", Name: "Program"}, &model.Program{ID: "p2", Name: "Outdated"}, &model.Program{ID: "p3"}}, 5, 5)
+	assert.NoError(t, err)
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{})
+
+	diff, err := indexerApi.Diff(ctx, Programs, previousAlias, latestAlias, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "programs-0", diff.From.IndexName)
+	assert.Equal(t, "programs-1", diff.To.IndexName)
+	assert.Equal(t, []string{"p4"}, diff.Added)
+	assert.Equal(t, []string{"p3"}, diff.Removed)
+	assert.Equal(t, []string{"p2"}, diff.Modified)
+	assert.Equal(t, 1, diff.Unchanged)
+	assert.Equal(t, []model.DocumentDiff{{ID: "p2", Fields: []model.FieldDiff{{Field: "Name", From: "Outdated", To: "Renamed"}}}}, diff.Samples)
+}
+
+func Test_Diff_WhenGenerationIsUnknown(t *testing.T) {
+	indexerApi := newTestIndexerApi(newIndexerWithLatest(t), fakeProgramAdapter{})
+
+	_, err := indexerApi.Diff(context.Background(), Programs, "oldest", latestAlias, 0)
+	assert.ErrorIs(t, err, ErrUnknownGeneration)
+	_, err = indexerApi.Diff(context.Background(), Programs, previousAlias, latestAlias, 0)
+	assert.ErrorIs(t, err, ErrNoGeneration)
+}
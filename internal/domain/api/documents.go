@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrDocumentRejected is returned when the search engine refuses to index a document.
+var ErrDocumentRejected = errors.New("document rejected by the search engine")
+
+// IndexDocument indexes the current state of a single entity of the docs type.
+// The document is removed instead if the entity no longer exists. The programs of an episode, before and after
+// the change, are reindexed too when their number of episodes is enriched.
+func (api indexerApi) IndexDocument(ctx context.Context, docsType, id string) error {
+	indexNames, err := api.documentIndexes(ctx, docsType)
+	if err != nil {
+		return err
+	}
+	previous := api.indexedPrograms(ctx, indexNames[0], docsType, id)
+
+	item, err := api.loadOne(ctx, docsType, id)
+	if errors.Is(err, port.ErrNotFound) {
+		log.Ctx(ctx).Debug().Str("docsType", docsType).Str("id", id).Msg("entity not found, document removed")
+		if err := api.deleteDocument(ctx, indexNames, id); err != nil {
+			return err
+		}
+		return api.refreshPrograms(ctx, previous...)
+	}
+	if err != nil {
+		return fmt.Errorf("could not find %s %s: %w", docsType, id, err)
+	}
+
+	if err := api.recordDocument(ctx, indexNames, docsType, id, item, false); err != nil {
+		return err
+	}
+	return api.refreshPrograms(ctx, append(previous, episodeProgram(item))...)
+}
+
+// DeleteDocument removes the document of a single entity of the docs type.
+// The program of an episode is reindexed too when its number of episodes is enriched.
+func (api indexerApi) DeleteDocument(ctx context.Context, docsType, id string) error {
+	indexNames, err := api.documentIndexes(ctx, docsType)
+	if err != nil {
+		return err
+	}
+	previous := api.indexedPrograms(ctx, indexNames[0], docsType, id)
+	if err := api.deleteDocument(ctx, indexNames, id); err != nil {
+		return err
+	}
+	return api.refreshPrograms(ctx, previous...)
+}
+
+// Handle executes a command received from a message queue: entity commands index or remove a single document
+// and reindex commands start an indexation job, unless one is already running for the docs type.
+// Commands that can never succeed are reported with model.ErrInvalidCommand.
+func (api indexerApi) Handle(ctx context.Context, command model.Command) error {
+	if !isDocsType(command.DocsType) {
+		return fmt.Errorf("%w: unknown docs type %q", model.ErrInvalidCommand, command.DocsType)
+	}
+
+	switch command.Type {
+	case model.CommandEntityUpserted, model.CommandEntityDeleted:
+		if command.ID == "" {
+			return fmt.Errorf("%w: missing entity id", model.ErrInvalidCommand)
+		}
+		if command.Type == model.CommandEntityDeleted {
+			return api.DeleteDocument(ctx, command.DocsType, command.ID)
+		}
+		return api.IndexDocument(ctx, command.DocsType, command.ID)
+	case model.CommandReindexRequested:
+		request := JobRequest{DocsType: command.DocsType, Mode: command.Mode, Trigger: model.TriggerMessage}
+		if request.Mode == "" {
+			request.Mode = ModeFull
+		}
+		if command.Since != nil {
+			request.Since = *command.Since
+		}
+		job, err := api.Start(ctx, request)
+		if errors.Is(err, ErrUnsupportedMode) {
+			return fmt.Errorf("%w: %s", model.ErrInvalidCommand, err)
+		}
+		if errors.Is(err, ErrJobInProgress) {
+			log.Ctx(ctx).Info().Str("job", job.ID).Msg("indexation already in progress, reindex command ignored")
+			return nil
+		}
+		return err
+	default:
+		return fmt.Errorf("%w: unknown type %q", model.ErrInvalidCommand, command.Type)
+	}
+}
+
+// Apply applies a change captured from the database: the document carried by an upsert is indexed as is,
+// and the document of a deleted entity is removed. The programs of an episode, before and after the change,
+// are reindexed too when their number of episodes is enriched.
+func (api indexerApi) Apply(ctx context.Context, change model.Change) error {
+	if !isDocsType(change.DocsType) {
+		return ErrUnknownDocsType
+	}
+	indexNames, err := api.documentIndexes(ctx, change.DocsType)
+	if err != nil {
+		return err
+	}
+	previous := api.indexedPrograms(ctx, indexNames[0], change.DocsType, change.ID)
+	if change.Operation == model.ChangeDelete {
+		if err := api.deleteDocument(ctx, indexNames, change.ID); err != nil {
+			return err
+		}
+		return api.refreshPrograms(ctx, previous...)
+	}
+	if err := api.recordDocument(ctx, indexNames, change.DocsType, change.ID, change.Document, false); err != nil {
+		return err
+	}
+	return api.refreshPrograms(ctx, append(previous, episodeProgram(change.Document))...)
+}
+
+// documentIndexes returns the indexes of the docs type receiving single-document changes: the latest index,
+// and the index being built by a running full indexation so that the change is not lost by its promotion.
+func (api indexerApi) documentIndexes(ctx context.Context, docsType string) ([]string, error) {
+	latestIndexName := api.aliasedIndex(ctx, latestAlias, docsType)
+	if latestIndexName == "" {
+		return nil, ErrNoLatestIndex
+	}
+	indexNames := []string{latestIndexName}
+	if inProgressIndexName := api.aliasedIndex(ctx, inProgressAlias, docsType); inProgressIndexName != "" {
+		indexNames = append(indexNames, inProgressIndexName)
+	}
+	return indexNames, nil
+}
+
+// recordDocument prepares the document of a single entity and indexes it into the indexes.
+// A document older than the indexed one is skipped, unless overwrite is set: the document then replaces the indexed one
+// whatever their versions, for the documents whose content changes without their entity, see refreshPrograms.
+// A document left out by an enricher or a data-quality rule is removed from the indexes instead, as a full indexation
+// would leave it out.
+func (api indexerApi) recordDocument(ctx context.Context, indexNames []string, docsType, id string, item interface{}, overwrite bool) error {
+	kept, _, err := api.prepare(ctx, docsType, []interface{}{item})
+	if err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		log.Ctx(ctx).Debug().Str("docsType", docsType).Str("id", id).Msg("document left out, document removed")
+		return api.deleteDocument(ctx, indexNames, id)
+	}
+	record := api.indexer.RecordBulkItems
+	if overwrite {
+		record = api.indexer.OverwriteBulkItems
+	}
+	for _, indexName := range indexNames {
+		result, err := record(ctx, indexName, []interface{}{item}, 5, 5)
+		if err != nil {
+			return fmt.Errorf("could not record %s %s: %w", docsType, id, err)
+		}
+		// An overwritten document is never stale, one reported as such was not written
+		if result.Failed > 0 || overwrite && result.Stale > 0 {
+			return fmt.Errorf("could not record %s %s: %w", docsType, id, ErrDocumentRejected)
+		}
+		if result.Stale > 0 {
+			log.Ctx(ctx).Debug().Str("docsType", docsType).Str("id", id).Str("index", indexName).Msg("stale document skipped")
+		}
+	}
+	return nil
+}
+
+// deleteDocument removes the document with the given identifier from the indexes.
+func (api indexerApi) deleteDocument(ctx context.Context, indexNames []string, id string) error {
+	for _, indexName := range indexNames {
+		if err := api.indexer.DeleteDocument(ctx, indexName, id); err != nil {
+			return fmt.Errorf("could not delete document %s from %s: %w", id, indexName, err)
+		}
+	}
+	return nil
+}
+
+// countsEpisodes reports whether the program documents are enriched with their number of episodes.
+func (api indexerApi) countsEpisodes() bool {
+	for _, enrichment := range api.enrichers[Programs] {
+		if enrichment.enricher.Name() == EnricherEpisodeCount {
+			return true
+		}
+	}
+	return false
+}
+
+// indexedPrograms returns the programs of the episodes with the given identifiers as indexed in the index, before
+// they change, so that the programs they leave can be reindexed. The episodes are retrieved in a single request.
+// It returns none for other docs types, or when the number of episodes of the programs is not enriched.
+func (api indexerApi) indexedPrograms(ctx context.Context, indexName, docsType string, ids ...string) []string {
+	if docsType != Episodes || !api.countsEpisodes() || len(ids) == 0 {
+		return nil
+	}
+	sources, err := api.indexer.Documents(ctx, indexName, ids)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("could not find the programs of the indexed episodes")
+		return nil
+	}
+	var programIDs []string
+	for id, source := range sources {
+		var episode struct {
+			ProgramID string `json:"ProgramID"`
+		}
+		if err := json.Unmarshal(source, &episode); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("id", id).Msg("could not decode the indexed episode")
+			continue
+		}
+		programIDs = append(programIDs, episode.ProgramID)
+	}
+	return programIDs
+}
+
+// refreshPrograms reindexes the programs whose episodes changed, so that their number of episodes follows.
+// It does nothing unless the number of episodes of the programs is enriched, and skips the programs before their
+// first full indexation. The version of a program only follows the program itself, not its episodes, so the refreshed
+// programs overwrite the indexed ones instead of being skipped as stale.
+func (api indexerApi) refreshPrograms(ctx context.Context, programIDs ...string) error {
+	if !api.countsEpisodes() {
+		return nil
+	}
+	indexNames, err := api.documentIndexes(ctx, Programs)
+	if errors.Is(err, ErrNoLatestIndex) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// The programs are not the documents of the job that changed their episodes, if any
+	ctx = withoutProgress(ctx)
+	refreshed := make(map[string]struct{}, len(programIDs))
+	for _, programID := range programIDs {
+		if _, ok := refreshed[programID]; ok || programID == "" {
+			continue
+		}
+		refreshed[programID] = struct{}{}
+		if err := api.refreshProgram(ctx, indexNames, programID); err != nil {
+			return fmt.Errorf("could not refresh program %s: %w", programID, err)
+		}
+	}
+	return nil
+}
+
+// refreshProgram overwrites the document of a program in the indexes with its current state, or removes it if the
+// program no longer exists.
+func (api indexerApi) refreshProgram(ctx context.Context, indexNames []string, programID string) error {
+	program, err := api.loadOne(ctx, Programs, programID)
+	if errors.Is(err, port.ErrNotFound) {
+		return api.deleteDocument(ctx, indexNames, programID)
+	}
+	if err != nil {
+		return fmt.Errorf("could not find %s %s: %w", Programs, programID, err)
+	}
+	return api.recordDocument(ctx, indexNames, Programs, programID, program, true)
+}
+
+// episodeProgram returns the program of an episode document, none for other documents.
+func episodeProgram(item interface{}) string {
+	if episode, ok := item.(*model.Episode); ok {
+		return episode.ProgramID
+	}
+	return ""
+}
+
+// loadOne retrieves the document of a single entity of the given docs type from the persistence layer.
+func (api indexerApi) loadOne(ctx context.Context, docsType, id string) (interface{}, error) {
+	switch docsType {
+	case Cats:
+		return api.catAdapter.Find(ctx, id)
+	case Tags:
+		return api.tagAdapter.Find(ctx, id)
+	case Walls:
+		return api.wallAdapter.Find(ctx, id)
+	case Blocks:
+		return api.blockAdapter.Find(ctx, id)
+	case Programs:
+		return api.programAdapter.Find(ctx, id)
+	case Episodes:
+		return api.episodeAdapter.Find(ctx, id)
+	case Medias:
+		return api.mediaAdapter.Find(ctx, id)
+	default:
+		return nil, ErrUnknownDocsType
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newIndexerWithLatest(t *testing.T, items ...interface{}) *fakeIndexer {
+	indexer := newFakeIndexer()
+	ctx := context.Background()
+	assert.NoError(t, indexer.CreateIndex(ctx, "programs-1", Programs, model.SearchTerms{}))
+	assert.NoError(t, indexer.CreateAlias(ctx, "programs-1", latestAlias))
+	_, err := indexer.RecordBulkItems(ctx, "programs-1", items, 5, 5)
+	assert.NoError(t, err)
+	return indexer
+}
+
+func Test_Handle_WhenEntityIsUpserted(t *testing.T) {
+	indexer := newIndexerWithLatest(t)
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}})
+
+	err := indexerApi.Handle(context.Background(), model.Command{Type: model.CommandEntityUpserted, DocsType: Programs, ID: "p1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{&model.Program{ID: "p1", Name: "Program"}}, indexer.documents("programs-1"))
+}
+
+func Test_Handle_WhenIndexedDocumentIsFresher(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1", Name: "Fresher", Version: 2})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Stale", Version: 1}}})
+
+	err := indexerApi.Handle(context.Background(), model.Command{Type: model.CommandEntityUpserted, DocsType: Programs, ID: "p1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{&model.Program{ID: "p1", Name: "Fresher", Version: 2}}, indexer.documents("programs-1"), "Stale document should be skipped")
+}
+
+func Test_Handle_WhenUpsertedEntityIsMissing(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1"})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{})
+
+	err := indexerApi.Handle(context.Background(), model.Command{Type: model.CommandEntityUpserted, DocsType: Programs, ID: "p1"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, indexer.documents("programs-1"), "Document of a missing entity should be removed")
+}
+
+func Test_Handle_WhenUpsertedEntityViolatesQuality(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1", Name: "Program"})
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "<p> </p>"}}}, nil, nil,
+		WithBuiltinEnrichers(map[string][]string{Programs: {EnricherNormalize}}))
+
+	err := indexerApi.Handle(context.Background(), model.Command{Type: model.CommandEntityUpserted, DocsType: Programs, ID: "p1"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, indexer.documents("programs-1"), "Document whose name is blank once normalized should be removed")
+}
+
+func Test_Handle_WhenEpisodeMovesToAnotherProgram(t *testing.T) {
+	one, two := 1, 2
+	indexer := newIndexerWithLatest(t,
+		&model.Program{ID: "p1", Name: "First", Version: 5, EpisodeCount: &two},
+		&model.Program{ID: "p2", Name: "Second", Version: 7, EpisodeCount: &one})
+	ctx := context.Background()
+	assert.NoError(t, indexer.CreateIndex(ctx, "episodes-1", Episodes, model.SearchTerms{}))
+	assert.NoError(t, indexer.CreateAlias(ctx, "episodes-1", latestAlias))
+	_, err := indexer.RecordBulkItems(ctx, "episodes-1", []interface{}{&model.Episode{ID: "e1", Name: "Episode", ProgramID: "p1"}}, 5, 5)
+	assert.NoError(t, err)
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil,
+		// The programs did not change since they were indexed, only their episodes did
+		fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "First", Version: 5}, {ID: "p2", Name: "Second", Version: 7}}},
+		fakeEpisodeAdapter{episodes: []*model.Episode{{ID: "e1", Name: "Episode", ProgramID: "p2"}, {ID: "e2", Name: "Episode", ProgramID: "p1"}, {ID: "e3", Name: "Episode", ProgramID: "p2"}}},
+		nil,
+		WithBuiltinEnrichers(map[string][]string{Programs: {EnricherEpisodeCount}}))
+
+	err = indexerApi.Handle(ctx, model.Command{Type: model.CommandEntityUpserted, DocsType: Episodes, ID: "e1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		&model.Program{ID: "p1", Name: "First", Version: 5, EpisodeCount: &one},
+		&model.Program{ID: "p2", Name: "Second", Version: 7, EpisodeCount: &two},
+	}, indexer.documents("programs-1"), "Should refresh the programs the episode left and joined despite their unchanged versions")
+}
+
+func Test_Handle_WhenEntityIsDeleted(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1"}, &model.Program{ID: "p2"})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{})
+
+	err := indexerApi.Handle(context.Background(), model.Command{Type: model.CommandEntityDeleted, DocsType: Programs, ID: "p1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{&model.Program{ID: "p2"}}, indexer.documents("programs-1"))
+}
+
+func Test_Handle_WhenCommandIsInvalid(t *testing.T) {
+	indexerApi := newTestIndexerApi(newIndexerWithLatest(t), fakeProgramAdapter{})
+
+	for _, command := range []model.Command{
+		{Type: "entity.renamed", DocsType: Programs, ID: "p1"},
+		{Type: model.CommandEntityUpserted, DocsType: "podcasts", ID: "p1"},
+		{Type: model.CommandEntityDeleted, DocsType: Programs},
+		{Type: model.CommandReindexRequested, DocsType: Medias, Mode: ModeDelta},
+	} {
+		err := indexerApi.Handle(context.Background(), command)
+
+		assert.ErrorIs(t, err, model.ErrInvalidCommand, "Command %+v should be invalid", command)
+	}
+}
+
+func Test_Apply_WhenChangesAreCaptured(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1"})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{})
+	ctx := context.Background()
+
+	assert.NoError(t, indexerApi.Apply(ctx, model.Change{Operation: model.ChangeUpsert, DocsType: Programs, ID: "p2", Document: &model.Program{ID: "p2", Name: "Program"}}))
+	assert.NoError(t, indexerApi.Apply(ctx, model.Change{Operation: model.ChangeDelete, DocsType: Programs, ID: "p1"}))
+
+	assert.Equal(t, []interface{}{&model.Program{ID: "p2", Name: "Program"}}, indexer.documents("programs-1"))
+}
+
+func Test_Start_WhenDeltaRemovesSoftDeletedEntities(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1"}, &model.Program{ID: "p2"})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{{ID: "p3", Name: "Program"}}, deleted: []string{"p1"}})
+	ctx := context.Background()
+
+	job, err := indexerApi.Start(ctx, JobRequest{DocsType: Programs, Mode: ModeDelta})
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(ctx, job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, job.Status)
+	assert.Equal(t, 1, job.Progress.Removed)
+	assert.Equal(t, []interface{}{&model.Program{ID: "p2"}, &model.Program{ID: "p3", Name: "Program"}}, indexer.documents("programs-1"))
+}
+
+func Test_Start_WhenReconcileRemovesHardDeletedEntities(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1"}, &model.Program{ID: "p2"}, &model.Program{ID: "p3"})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{{ID: "p2"}}})
+	ctx := context.Background()
+
+	job, err := indexerApi.Start(ctx, JobRequest{DocsType: Programs, Mode: ModeReconcile})
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(ctx, job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, job.Status)
+	assert.Equal(t, 2, job.Progress.Removed)
+	assert.Equal(t, []interface{}{&model.Program{ID: "p2"}}, indexer.documents("programs-1"))
+}
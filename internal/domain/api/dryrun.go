@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// dryRunSampleSize is the maximum number of sample documents returned by a dry run.
+const dryRunSampleSize = 3
+
+// DryRunReport describes what an indexation of a docs type would do.
+type DryRunReport struct {
+	DocsType   string                   `json:"docsType"`
+	IndexName  string                   `json:"indexName"`
+	Documents  int                      `json:"documents"`
+	Invalid    int                      `json:"invalid"`
+	Dropped    int                      `json:"dropped"`
+	Samples    []interface{}            `json:"samples"`
+	Issues     []model.DocumentIssue    `json:"issues"`
+	Quality    []model.QualityViolation `json:"quality"`
+	Operations []Operation              `json:"operations"`
+}
+
+// DryRun reads, checks and validates the documents of the docs type and reports the index and alias
+// operations an indexation would perform, without creating or deleting anything.
+func (api indexerApi) DryRun(ctx context.Context, docsType string) (DryRunReport, error) {
+	indexName := newIndexName(docsType)
+
+	// Retrieve all documents
+	items, err := api.load(ctx, docsType)
+	if err != nil {
+		return DryRunReport{}, fmt.Errorf("could not find all %s: %w", docsType, err)
+	}
+
+	// Enrich documents and check them against the data-quality rules, a violation failing the run is reported
+	// instead of returned
+	loaded := len(items)
+	items, violations, err := api.prepare(ctx, docsType, items)
+	if err != nil && !errors.Is(err, ErrQualityCheckFailed) {
+		return DryRunReport{}, err
+	}
+
+	// Validate documents against the mapping of the docs type
+	issues, err := api.indexer.ValidateDocuments(ctx, docsType, items)
+	if err != nil {
+		return DryRunReport{}, fmt.Errorf("could not validate %s: %w", docsType, err)
+	}
+
+	samples := items
+	if len(samples) > dryRunSampleSize {
+		samples = samples[:dryRunSampleSize]
+	}
+
+	return DryRunReport{
+		DocsType:   docsType,
+		IndexName:  indexName,
+		Documents:  len(items),
+		Invalid:    countInvalidDocuments(issues),
+		Dropped:    loaded - len(items),
+		Samples:    samples,
+		Issues:     issues,
+		Quality:    violations,
+		Operations: append(preparationPlan(indexName), api.promotionPlan(ctx, docsType, indexName)...),
+	}, nil
+}
+
+// countInvalidDocuments returns the number of distinct documents having at least one issue.
+func countInvalidDocuments(issues []model.DocumentIssue) int {
+	invalid := make(map[string]struct{})
+	for _, issue := range issues {
+		if issue.DocumentID != "" {
+			invalid[issue.DocumentID] = struct{}{}
+		}
+	}
+	return len(invalid)
+}
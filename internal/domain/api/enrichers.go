@@ -0,0 +1,258 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/khedhrije/podcaster-indexer-api/pkg/text"
+	"strings"
+	"unicode"
+)
+
+// Names of the built-in enrichers.
+const (
+	EnricherNormalize    = "normalize"     // Normalizes the name and description of every docs type but medias into plain text
+	EnricherLanguages    = "languages"     // Sets the localized name and description of the docs types with languages configured
+	EnricherSlug         = "slug"          // Sets the slug of the name of every docs type but medias
+	EnricherEpisodeCount = "episode-count" // Sets the number of episodes of the programs
+	EnricherWordCount    = "word-count"    // Sets the number of words of the description of the programs and episodes
+)
+
+// builtinEnricher returns the built-in enricher with the given name, if it supports the docs type.
+func (api indexerApi) builtinEnricher(name, docsType string) (Enricher, bool) {
+	switch {
+	case name == EnricherNormalize && docsType != Medias:
+		return textNormalizer{summaryLength: api.summaryLength, keepRaw: api.keepRawDescription}, true
+	case name == EnricherLanguages && docsType != Medias && len(api.languages[docsType]) > 0:
+		return languageEnricher{languages: api.languages[docsType], detect: api.detectLanguage}, true
+	case name == EnricherSlug && docsType != Medias:
+		return enricherFunc{name: name, enrich: slugEnricher}, true
+	case name == EnricherEpisodeCount && docsType == Programs:
+		return episodeCountEnricher{episodeAdapter: api.episodeAdapter}, true
+	case name == EnricherWordCount && (docsType == Programs || docsType == Episodes):
+		return enricherFunc{name: name, enrich: wordCountEnricher}, true
+	default:
+		return nil, false
+	}
+}
+
+// enricherFunc is an enricher enriching each document on its own, without loading anything.
+type enricherFunc struct {
+	name   string
+	enrich func(document interface{}) error
+}
+
+// Name returns the name of the enricher.
+func (e enricherFunc) Name() string {
+	return e.name
+}
+
+// Prepare returns the enrich function of the enricher.
+func (e enricherFunc) Prepare(ctx context.Context, documents []interface{}) (func(document interface{}) error, error) {
+	return e.enrich, nil
+}
+
+// slugEnricher sets the slug of a document from its name.
+func slugEnricher(document interface{}) error {
+	switch document := document.(type) {
+	case *model.Category:
+		document.Slug = slugify(document.Name)
+	case *model.Tag:
+		document.Slug = slugify(document.Name)
+	case *model.Wall:
+		document.Slug = slugify(document.Name)
+	case *model.Block:
+		document.Slug = slugify(document.Name)
+	case *model.Program:
+		document.Slug = slugify(document.Name)
+	case *model.Episode:
+		document.Slug = slugify(document.Name)
+	default:
+		return ErrUnsupportedDocument
+	}
+	return nil
+}
+
+// wordCountEnricher sets the number of words of the description of a document.
+func wordCountEnricher(document interface{}) error {
+	switch document := document.(type) {
+	case *model.Program:
+		words := len(strings.Fields(document.Description))
+		document.WordCount = &words
+	case *model.Episode:
+		words := len(strings.Fields(document.Description))
+		document.WordCount = &words
+	default:
+		return ErrUnsupportedDocument
+	}
+	return nil
+}
+
+// textNormalizer normalizes the name and the description of the documents into plain text, see text.PlainText,
+// and sets their summary to the beginning of the description. The raw description is kept on request.
+type textNormalizer struct {
+	summaryLength int  // Maximum length of the summaries, none are set if not positive
+	keepRaw       bool // Whether the raw description is kept
+}
+
+// Name returns the name of the enricher.
+func (n textNormalizer) Name() string {
+	return EnricherNormalize
+}
+
+// Prepare returns the normalize function of the enricher.
+func (n textNormalizer) Prepare(ctx context.Context, documents []interface{}) (func(document interface{}) error, error) {
+	return n.normalize, nil
+}
+
+// normalize normalizes the text fields of a document.
+func (n textNormalizer) normalize(document interface{}) error {
+	var name, description, summary, raw *string
+	switch document := document.(type) {
+	case *model.Category:
+		name, description, summary, raw = &document.Name, &document.Description, &document.Summary, &document.RawDescription
+	case *model.Tag:
+		name, description, summary, raw = &document.Name, &document.Description, &document.Summary, &document.RawDescription
+	case *model.Wall:
+		name, description, summary, raw = &document.Name, &document.Description, &document.Summary, &document.RawDescription
+	case *model.Block:
+		name, description, summary, raw = &document.Name, &document.Description, &document.Summary, &document.RawDescription
+	case *model.Program:
+		name, description, summary, raw = &document.Name, &document.Description, &document.Summary, &document.RawDescription
+	case *model.Episode:
+		name, description, summary, raw = &document.Name, &document.Description, &document.Summary, &document.RawDescription
+	default:
+		return ErrUnsupportedDocument
+	}
+
+	if n.keepRaw {
+		*raw = *description
+	}
+	*name = text.PlainText(*name)
+	*description = text.PlainText(*description)
+	if n.summaryLength > 0 {
+		*summary = text.Truncate(*description, n.summaryLength)
+	}
+	return nil
+}
+
+// languageEnricher sets the localized name and description of the documents in the languages of their docs type,
+// or only in the language detected in them when detection is enabled and succeeds.
+type languageEnricher struct {
+	languages []string // Languages of the docs type
+	detect    bool     // Whether the language of each document is detected
+}
+
+// Name returns the name of the enricher.
+func (e languageEnricher) Name() string {
+	return EnricherLanguages
+}
+
+// Prepare returns the localize function of the enricher.
+func (e languageEnricher) Prepare(ctx context.Context, documents []interface{}) (func(document interface{}) error, error) {
+	return e.localize, nil
+}
+
+// localize sets the localized text fields of a document.
+func (e languageEnricher) localize(document interface{}) error {
+	var name, description string
+	var language *string
+	var localizedName, localizedDescription *model.LocalizedText
+	switch document := document.(type) {
+	case *model.Category:
+		name, description, language = document.Name, document.Description, &document.Language
+		localizedName, localizedDescription = &document.LocalizedName, &document.LocalizedDescription
+	case *model.Tag:
+		name, description, language = document.Name, document.Description, &document.Language
+		localizedName, localizedDescription = &document.LocalizedName, &document.LocalizedDescription
+	case *model.Wall:
+		name, description, language = document.Name, document.Description, &document.Language
+		localizedName, localizedDescription = &document.LocalizedName, &document.LocalizedDescription
+	case *model.Block:
+		name, description, language = document.Name, document.Description, &document.Language
+		localizedName, localizedDescription = &document.LocalizedName, &document.LocalizedDescription
+	case *model.Program:
+		name, description, language = document.Name, document.Description, &document.Language
+		localizedName, localizedDescription = &document.LocalizedName, &document.LocalizedDescription
+	case *model.Episode:
+		name, description, language = document.Name, document.Description, &document.Language
+		localizedName, localizedDescription = &document.LocalizedName, &document.LocalizedDescription
+	default:
+		return ErrUnsupportedDocument
+	}
+
+	languages := e.languages
+	if e.detect {
+		*language = text.DetectLanguage(name+" "+description, e.languages)
+		if *language != "" {
+			languages = []string{*language}
+		}
+	}
+	*localizedName = localize(name, languages)
+	*localizedDescription = localize(description, languages)
+	return nil
+}
+
+// localize returns the text in each of the languages, or nil if the text is empty.
+func localize(value string, languages []string) model.LocalizedText {
+	if value == "" {
+		return nil
+	}
+	localized := make(model.LocalizedText, len(languages))
+	for _, language := range languages {
+		localized[language] = value
+	}
+	return localized
+}
+
+// episodeCountEnricher sets the number of episodes of the program documents, counted at once for all of them.
+type episodeCountEnricher struct {
+	episodeAdapter port.Episode
+}
+
+// Name returns the name of the enricher.
+func (e episodeCountEnricher) Name() string {
+	return EnricherEpisodeCount
+}
+
+// Prepare counts the episodes of the program documents.
+func (e episodeCountEnricher) Prepare(ctx context.Context, documents []interface{}) (func(document interface{}) error, error) {
+	programIDs := make([]string, 0, len(documents))
+	for _, document := range documents {
+		if program, ok := document.(*model.Program); ok {
+			programIDs = append(programIDs, program.ID)
+		}
+	}
+	counts, err := e.episodeAdapter.CountByProgram(ctx, programIDs)
+	if err != nil {
+		return nil, err
+	}
+	return func(document interface{}) error {
+		program, ok := document.(*model.Program)
+		if !ok {
+			return ErrUnsupportedDocument
+		}
+		count := counts[program.ID]
+		program.EpisodeCount = &count
+		return nil
+	}, nil
+}
+
+// slugify returns the lower-case form of the text with its runs of characters other than letters and digits
+// replaced by single hyphens, e.g. "The Daily Show!" becomes "the-daily-show".
+func slugify(text string) string {
+	var slug strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return slug.String()
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrUnsupportedDocument is returned by an enricher given a document it cannot enrich.
+var ErrUnsupportedDocument = errors.New("document not supported by the enricher")
+
+// Enricher sets derived fields on the documents of a docs type before they are indexed.
+type Enricher interface {
+	// Name identifies the enricher in the configuration and the logs.
+	Name() string
+	// Prepare returns the function enriching each document, given all the documents about to be indexed
+	// so that the data they need can be loaded at once.
+	Prepare(ctx context.Context, documents []interface{}) (func(document interface{}) error, error)
+}
+
+// enrichment is an enricher registered for a docs type, along with the handling of its errors.
+type enrichment struct {
+	enricher Enricher
+	onError  string // Handling of the documents it fails to enrich: model.SeverityWarn, model.SeverityDrop or model.SeverityFail
+}
+
+// enrich runs the enrichers of the docs type in their registration order on each document and returns the documents
+// to index. A document an enricher fails to enrich is indexed as is, or left out, or fails the whole run,
+// depending on the error handling of the enricher. Failing to prepare an enricher fails every document.
+func (api indexerApi) enrich(ctx context.Context, docsType string, items []interface{}) ([]interface{}, error) {
+	enrichments := api.enrichers[docsType]
+	if len(enrichments) == 0 || len(items) == 0 {
+		return items, nil
+	}
+
+	dropped := make(map[int]struct{})
+	for _, enrichment := range enrichments {
+		name := enrichment.enricher.Name()
+		apply, err := enrichment.enricher.Prepare(ctx, items)
+		if err != nil {
+			apply = func(interface{}) error { return err }
+		}
+
+		failed := 0
+		for i, item := range items {
+			if _, ok := dropped[i]; ok {
+				continue
+			}
+			err := apply(item)
+			if err == nil {
+				continue
+			}
+			failed++
+			switch enrichment.onError {
+			case model.SeverityFail:
+				return nil, fmt.Errorf("could not enrich %s %s with %s: %w", docsType, documentID(item), name, err)
+			case model.SeverityDrop:
+				dropped[i] = struct{}{}
+			}
+			log.Ctx(ctx).Debug().Err(err).Str("enricher", name).Str("id", documentID(item)).Msg("could not enrich document")
+		}
+		if failed > 0 {
+			log.Ctx(ctx).Warn().Str("enricher", name).Str("onError", enrichment.onError).Int("failed", failed).Msg("documents not enriched")
+		}
+	}
+
+	if len(dropped) == 0 {
+		return items, nil
+	}
+	kept := make([]interface{}, 0, len(items)-len(dropped))
+	for i, item := range items {
+		if _, ok := dropped[i]; !ok {
+			kept = append(kept, item)
+		}
+	}
+	reportProgress(ctx, func(progress *model.Progress) {
+		progress.Dropped += len(dropped)
+	})
+	return kept, nil
+}
+
+// prepare enriches the documents of the docs type, then checks them against its data-quality rules, so that the rules
+// apply to the normalized documents. It is the step shared by every path writing documents, and returns the documents
+// to index along with the violations found, see checkQuality.
+func (api indexerApi) prepare(ctx context.Context, docsType string, items []interface{}) ([]interface{}, []model.QualityViolation, error) {
+	items, err := api.enrich(ctx, docsType, items)
+	if err != nil {
+		return nil, nil, err
+	}
+	kept, violations, err := api.checkQuality(ctx, docsType, items)
+	if err != nil {
+		return kept, violations, fmt.Errorf("could not check quality of %s: %w", docsType, err)
+	}
+	return kept, violations, nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// failingEnricher is an enricher failing to enrich the documents with the given identifiers.
+type failingEnricher struct {
+	ids []string
+}
+
+func (f failingEnricher) Name() string {
+	return "failing"
+}
+
+func (f failingEnricher) Prepare(ctx context.Context, documents []interface{}) (func(document interface{}) error, error) {
+	return func(document interface{}) error {
+		for _, id := range f.ids {
+			if documentID(document) == id {
+				return errors.New("enrichment failed")
+			}
+		}
+		return nil
+	}, nil
+}
+
+func Test_Start_WhenBuiltinEnrichersAreRegistered(t *testing.T) {
+	indexer := newFakeIndexer()
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil,
+		fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "The Daily Show!", Description: "News of the day"}}},
+		fakeEpisodeAdapter{episodes: []*model.Episode{{ID: "e1", ProgramID: "p1"}, {ID: "e2", ProgramID: "p1"}}},
+		nil,
+		WithBuiltinEnrichers(map[string][]string{Programs: {EnricherSlug, EnricherEpisodeCount, EnricherWordCount, "unknown"}}))
+	ctx := context.Background()
+
+	job, err := indexerApi.Start(ctx, JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	finished, err := indexerApi.Wait(ctx, job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, finished.Status)
+	episodes, words := 2, 4
+	assert.Equal(t, []interface{}{&model.Program{
+		ID:           "p1",
+		Name:         "The Daily Show!",
+		Description:  "News of the day",
+		Slug:         "the-daily-show",
+		EpisodeCount: &episodes,
+		WordCount:    &words,
+	}}, indexer.documents(finished.IndexName))
+}
+
+func Test_Enrich_WhenEnricherFails(t *testing.T) {
+	items := []interface{}{&model.Program{ID: "p1"}, &model.Program{ID: "p2"}}
+	ctx := context.Background()
+
+	dropping := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{}, nil, nil,
+		WithEnrichers(Programs, model.SeverityDrop, failingEnricher{ids: []string{"p1"}})).(*indexerApi)
+	kept, err := dropping.enrich(ctx, Programs, items)
+	assert.NoError(t, err)
+	assert.Equal(t, items[1:], kept, "Documents failing a dropping enricher should be left out")
+
+	warning := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{}, nil, nil,
+		WithEnrichers(Programs, model.SeverityWarn, failingEnricher{ids: []string{"p1"}})).(*indexerApi)
+	kept, err = warning.enrich(ctx, Programs, items)
+	assert.NoError(t, err)
+	assert.Equal(t, items, kept, "Documents failing a warning enricher should be kept")
+
+	failing := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{}, nil, nil,
+		WithEnrichers(Programs, model.SeverityFail, failingEnricher{ids: []string{"p2"}})).(*indexerApi)
+	_, err = failing.enrich(ctx, Programs, items)
+	assert.ErrorContains(t, err, "could not enrich programs p2 with failing")
+}
+
+func Test_Enrich_WhenTextIsNormalized(t *testing.T) {
+	indexerApi := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{}, nil, nil,
+		WithTextNormalization(12, true),
+		WithBuiltinEnrichers(map[string][]string{Episodes: {EnricherNormalize, EnricherSlug}})).(*indexerApi)
+	episode := &model.Episode{ID: "e1", Name: " Tom &amp; Jerry ", Description: "<p>The   cat and<br>the mouse</p>"}
+
+	_, err := indexerApi.enrich(context.Background(), Episodes, []interface{}{episode})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &model.Episode{
+		ID:             "e1",
+		Name:           "Tom & Jerry",
+		Description:    "The cat and the mouse",
+		Slug:           "tom-jerry",
+		Summary:        "The cat and…",
+		RawDescription: "<p>The   cat and<br>the mouse</p>",
+	}, episode)
+}
+
+func Test_Enrich_WhenLanguageIsDetected(t *testing.T) {
+	indexerApi := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{}, nil, nil,
+		WithLanguages(map[string][]string{Programs: {model.LanguageFrench, model.LanguageEnglish}}, true),
+		WithBuiltinEnrichers(map[string][]string{Programs: {EnricherLanguages}})).(*indexerApi)
+	french := &model.Program{ID: "p1", Name: "L'émission", Description: "Le journal de la rédaction"}
+	unknown := &model.Program{ID: "p2", Name: "Podcast"}
+
+	_, err := indexerApi.enrich(context.Background(), Programs, []interface{}{french, unknown})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fr", french.Language)
+	assert.Equal(t, model.LocalizedText{"fr": "L'émission"}, french.LocalizedName)
+	assert.Equal(t, model.LocalizedText{"fr": "Le journal de la rédaction"}, french.LocalizedDescription)
+	assert.Equal(t, "", unknown.Language)
+	assert.Equal(t, model.LocalizedText{"fr": "Podcast", "en": "Podcast"}, unknown.LocalizedName, "An undetected document should be localized in every language")
+	assert.Nil(t, unknown.LocalizedDescription)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// publishTimeout is the deadline of the publication of an event.
+const publishTimeout = 10 * time.Second
+
+// publish sends an index event to every publisher, along with the job running with the context, if any.
+// A failure is logged without failing the indexation.
+func (api indexerApi) publish(ctx context.Context, eventType string, data model.IndexEventData) {
+	if len(api.publishers) == 0 {
+		return
+	}
+	data.JobID = jobID(ctx)
+	event := model.IndexEvent{
+		Version:    model.IndexEventVersion,
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	for _, publisher := range api.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("event", eventType).Msg("could not publish index event")
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Pagination of the indexation history.
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+// historyTimeout is the deadline of a write to the history.
+const historyTimeout = 10 * time.Second
+
+// ErrNoHistory is returned when querying the history of an indexer api configured without one.
+var ErrNoHistory = errors.New("indexation history is not configured")
+
+// History returns the page of recorded jobs selected by the filter, newest first.
+// The limit defaults to DefaultHistoryLimit and is capped to MaxHistoryLimit.
+func (api indexerApi) History(ctx context.Context, filter model.JobFilter) (model.JobPage, error) {
+	if api.history == nil {
+		return model.JobPage{}, ErrNoHistory
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryLimit
+	}
+	filter.Limit = min(filter.Limit, MaxHistoryLimit)
+	filter.Offset = max(filter.Offset, 0)
+	return api.history.Find(ctx, filter)
+}
+
+// save records the state of a job in the history, if any. A failure is logged without failing the job.
+func (api indexerApi) save(ctx context.Context, job model.Job) {
+	if api.history == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), historyTimeout)
+	defer cancel()
+	if err := api.history.Save(ctx, job); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("status", job.Status).Msg("could not record job in history")
+	}
+}
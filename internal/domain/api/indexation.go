@@ -0,0 +1,314 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+const (
+	latestAlias     = "latest"
+	previousAlias   = "previous"
+	inProgressAlias = "in-progress"
+)
+
+// Actions that can be part of an indexation plan.
+const (
+	ActionCreateIndex = "create-index"
+	ActionDeleteIndex = "delete-index"
+	ActionCreateAlias = "create-alias"
+	ActionDeleteAlias = "delete-alias"
+)
+
+// bulkBatchSize is the number of documents sent per bulk request.
+const bulkBatchSize = 1000
+
+// ErrUnknownDocsType is returned when an operation targets a docs type the indexer does not handle.
+var ErrUnknownDocsType = errors.New("unknown docs type")
+
+// Operation is a single index or alias change performed by an indexation.
+type Operation struct {
+	Action string `json:"action"`
+	Index  string `json:"index"`
+	Alias  string `json:"alias,omitempty"`
+}
+
+// Reindex runs a full indexation of the given docs type.
+func (api indexerApi) Reindex(ctx context.Context, docsType string) error {
+	if !isDocsType(docsType) {
+		return ErrUnknownDocsType
+	}
+	return api.index(ctx, docsType)
+}
+
+// index runs a full indexation of the given docs type: a new index is created,
+// filled with every document of the docs type and then promoted to the latest alias.
+// A failure, unless caused by a cancellation, is published.
+func (api indexerApi) index(ctx context.Context, docsType string) (err error) {
+	// Initialize indexName with current Unix timestamp
+	indexName := newIndexName(docsType)
+	reportIndexName(ctx, indexName)
+
+	var documents int
+	defer func() {
+		if err != nil && !errors.Is(ctx.Err(), context.Canceled) {
+			api.publish(ctx, model.IndexEventFailed, model.IndexEventData{DocsType: docsType, IndexName: indexName, Documents: documents, Error: err.Error()})
+		}
+	}()
+
+	// Create the new index and assign it the in-progress alias
+	reportPhase(ctx, model.PhaseCreate)
+	if err := api.apply(ctx, docsType, preparationPlan(indexName)); err != nil {
+		return err
+	}
+
+	// Retrieve all documents
+	reportPhase(ctx, model.PhaseLoad)
+	items, err := api.load(ctx, docsType)
+	if err != nil {
+		api.discardIfCancelled(ctx, indexName)
+		return fmt.Errorf("could not find all %s: %w", docsType, err)
+	}
+	documents = len(items)
+	reportProgress(ctx, func(progress *model.Progress) {
+		progress.RowsRead = len(items)
+		progress.Documents = len(items)
+	})
+
+	// Enrich documents and check them against the data-quality rules, then validate them against the mapping
+	reportPhase(ctx, model.PhaseValidate)
+	items, _, err = api.prepare(ctx, docsType, items)
+	if err != nil {
+		api.discardIfCancelled(ctx, indexName)
+		return err
+	}
+	documents = len(items)
+	reportProgress(ctx, func(progress *model.Progress) {
+		progress.Documents = len(items)
+	})
+	api.validate(ctx, docsType, items)
+
+	// Bulk index documents
+	reportPhase(ctx, model.PhaseIndex)
+	if err := api.record(ctx, indexName, items); err != nil {
+		api.discardIfCancelled(ctx, indexName)
+		return fmt.Errorf("could not record bulk all %s: %w", docsType, err)
+	}
+
+	// A cancellation or timeout is honoured up to this point: past it the aliases must be rotated entirely
+	if err := ctx.Err(); err != nil {
+		api.discardIfCancelled(ctx, indexName)
+		return err
+	}
+
+	// Rotate the latest and previous aliases
+	reportPhase(ctx, model.PhasePromote)
+	return api.promote(context.WithoutCancel(ctx), docsType, indexName, documents)
+}
+
+// promote rotates the aliases to make indexName the latest index of the docs type and publishes the promotion.
+// If the rotation fails, it is rolled back so that the latest alias stays on the index holding it before.
+func (api indexerApi) promote(ctx context.Context, docsType, indexName string, documents int) error {
+	latestIndexName := api.aliasedIndex(ctx, latestAlias, docsType)
+	data := model.IndexEventData{DocsType: docsType, IndexName: indexName, PreviousIndexName: latestIndexName, Documents: documents}
+
+	if err := api.apply(ctx, docsType, api.promotionPlan(ctx, docsType, indexName)); err != nil {
+		if rollbackErr := api.apply(ctx, docsType, api.rollbackPlan(ctx, indexName, latestIndexName)); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("could not roll back promotion: %w", rollbackErr))
+		}
+		log.Ctx(ctx).Warn().Err(err).Str("index", indexName).Msg("promotion rolled back")
+		data.LatestIndexName = latestIndexName
+		api.publish(ctx, model.IndexEventRolledBack, data)
+		return err
+	}
+
+	data.LatestIndexName = indexName
+	api.publish(ctx, model.IndexEventPromoted, data)
+	return nil
+}
+
+// validate checks the documents against the mapping of the docs type.
+// Invalid documents are reported and logged, but still indexed.
+func (api indexerApi) validate(ctx context.Context, docsType string, items []interface{}) {
+	issues, err := api.indexer.ValidateDocuments(ctx, docsType, items)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("could not validate documents")
+		return
+	}
+	invalid := countInvalidDocuments(issues)
+	if invalid > 0 {
+		log.Ctx(ctx).Warn().Int("invalid", invalid).Msg("documents do not match the mapping")
+	}
+	reportProgress(ctx, func(progress *model.Progress) {
+		progress.Invalid = invalid
+	})
+}
+
+// record writes the items into the index in bulk requests of bulkBatchSize documents, stopping at the first failure.
+func (api indexerApi) record(ctx context.Context, indexName string, items []interface{}) error {
+	for start := 0; start < len(items); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(items))
+		result, err := api.indexer.RecordBulkItems(ctx, indexName, items[start:end], 5, 5)
+		reportProgress(ctx, func(progress *model.Progress) {
+			progress.Flushes++
+			progress.Indexed += result.Indexed
+			progress.Failures += result.Failed
+			progress.Stale += result.Stale
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discardIfCancelled deletes the partial index of an indexation whose context was cancelled or timed out.
+func (api indexerApi) discardIfCancelled(ctx context.Context, indexName string) {
+	if ctx.Err() == nil {
+		return
+	}
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	if err := api.indexer.DeleteIndexes(cleanupCtx, []string{indexName}); err != nil {
+		log.Error().Err(err).Str("index", indexName).Msg("could not delete partial index")
+	}
+}
+
+// load retrieves every document of the given docs type from the persistence layer.
+func (api indexerApi) load(ctx context.Context, docsType string) ([]interface{}, error) {
+	switch docsType {
+	case Cats:
+		categories, err := api.catAdapter.FindAll(ctx)
+		return mapArrayToInterface(categories), err
+	case Tags:
+		tags, err := api.tagAdapter.FindAll(ctx)
+		return mapArrayToInterface(tags), err
+	case Walls:
+		walls, err := api.wallAdapter.FindAll(ctx)
+		return mapArrayToInterface(walls), err
+	case Blocks:
+		blocks, err := api.blockAdapter.FindAll(ctx)
+		return mapArrayToInterface(blocks), err
+	case Programs:
+		programs, err := api.programAdapter.FindAll(ctx)
+		return mapArrayToInterface(programs), err
+	case Episodes:
+		episodes, err := api.episodeAdapter.FindAll(ctx)
+		return mapArrayToInterface(episodes), err
+	case Medias:
+		medias, err := api.mediaAdapter.FindAll(ctx)
+		return mapArrayToInterface(medias), err
+	default:
+		return nil, ErrUnknownDocsType
+	}
+}
+
+// preparationPlan returns the operations creating a new index and marking it as in progress.
+func preparationPlan(indexName string) []Operation {
+	return []Operation{
+		{Action: ActionCreateIndex, Index: indexName},
+		{Action: ActionCreateAlias, Index: indexName, Alias: inProgressAlias},
+	}
+}
+
+// promotionPlan returns the operations making indexName the latest index of the docs type.
+// The current latest index becomes the previous one, and the current previous index is deleted.
+func (api indexerApi) promotionPlan(ctx context.Context, docsType, indexName string) []Operation {
+	latestIndexName := api.aliasedIndex(ctx, latestAlias, docsType)
+
+	// If there is no latest index, the in-progress index simply becomes the latest index
+	if latestIndexName == "" {
+		return []Operation{
+			{Action: ActionDeleteAlias, Index: indexName, Alias: inProgressAlias},
+			{Action: ActionCreateAlias, Index: indexName, Alias: latestAlias},
+		}
+	}
+
+	var plan []Operation
+
+	// Delete the previous index if any
+	if previousIndexName := api.aliasedIndex(ctx, previousAlias, docsType); previousIndexName != "" {
+		plan = append(plan, Operation{Action: ActionDeleteIndex, Index: previousIndexName})
+	}
+
+	return append(plan,
+		// Make the latest index the previous index
+		Operation{Action: ActionDeleteAlias, Index: latestIndexName, Alias: latestAlias},
+		Operation{Action: ActionCreateAlias, Index: latestIndexName, Alias: previousAlias},
+		// Make the in-progress index the latest index
+		Operation{Action: ActionDeleteAlias, Index: indexName, Alias: inProgressAlias},
+		Operation{Action: ActionCreateAlias, Index: indexName, Alias: latestAlias},
+	)
+}
+
+// rollbackPlan returns the operations undoing a failed promotion of indexName, latestIndexName being the index
+// holding the latest alias before it: that index gets the latest alias back and loses the previous alias,
+// and the new index is deleted along with its aliases. An old previous index already deleted cannot be restored.
+func (api indexerApi) rollbackPlan(ctx context.Context, indexName, latestIndexName string) []Operation {
+	var plan []Operation
+	if latestIndexName != "" {
+		if !api.hasAlias(ctx, latestAlias, latestIndexName) {
+			plan = append(plan, Operation{Action: ActionCreateAlias, Index: latestIndexName, Alias: latestAlias})
+		}
+		if api.hasAlias(ctx, previousAlias, latestIndexName) {
+			plan = append(plan, Operation{Action: ActionDeleteAlias, Index: latestIndexName, Alias: previousAlias})
+		}
+	}
+	return append(plan, Operation{Action: ActionDeleteIndex, Index: indexName})
+}
+
+// apply executes the operations of a plan in order, stopping at the first failure.
+func (api indexerApi) apply(ctx context.Context, docsType string, plan []Operation) error {
+	for _, op := range plan {
+		var err error
+		switch op.Action {
+		case ActionCreateIndex:
+			var terms model.SearchTerms
+			if terms, err = api.indexSearchTerms(ctx); err == nil {
+				err = api.indexer.CreateIndex(ctx, op.Index, docsType, terms)
+			}
+		case ActionDeleteIndex:
+			err = api.indexer.DeleteIndexes(ctx, []string{op.Index})
+		case ActionCreateAlias:
+			err = api.indexer.CreateAlias(ctx, op.Index, op.Alias)
+		case ActionDeleteAlias:
+			err = api.indexer.DeleteAlias(ctx, op.Index, op.Alias)
+		default:
+			err = fmt.Errorf("unknown action %q", op.Action)
+		}
+		if err != nil {
+			return fmt.Errorf("could not %s %s: %w", op.Action, op.Index, err)
+		}
+	}
+	return nil
+}
+
+// aliasedIndex returns the name of the index of the given docs type holding the alias, or an empty string.
+func (api indexerApi) aliasedIndex(ctx context.Context, aliasName, docsType string) string {
+	var indexName string
+	for _, name := range api.indexer.IndexByAlias(ctx, aliasName) {
+		if strings.HasPrefix(name, docsType+"-") {
+			indexName = name
+		}
+	}
+	return indexName
+}
+
+// hasAlias reports whether the index holds the alias.
+func (api indexerApi) hasAlias(ctx context.Context, aliasName, indexName string) bool {
+	for _, name := range api.indexer.IndexByAlias(ctx, aliasName) {
+		if name == indexName {
+			return true
+		}
+	}
+	return false
+}
+
+// newIndexName returns a unique index name for the docs type based on the current Unix timestamp.
+func newIndexName(docsType string) string {
+	return fmt.Sprintf("%s-%v", docsType, time.Now().Unix())
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"time"
+)
+
+// Integrity checks the references between the entities read from the persistence layer, as the indexations read them:
+// the episodes whose program does not exist, the medias whose episode does not exist, the categories whose parent
+// does not exist and the programs having no episode.
+func (api indexerApi) Integrity(ctx context.Context) (model.IntegrityReport, error) {
+	checkedAt := time.Now()
+	categories, err := api.catAdapter.FindAll(ctx)
+	if err != nil {
+		return model.IntegrityReport{}, fmt.Errorf("could not find all %s: %w", Cats, err)
+	}
+	programs, err := api.programAdapter.FindAll(ctx)
+	if err != nil {
+		return model.IntegrityReport{}, fmt.Errorf("could not find all %s: %w", Programs, err)
+	}
+	episodes, err := api.episodeAdapter.FindAll(ctx)
+	if err != nil {
+		return model.IntegrityReport{}, fmt.Errorf("could not find all %s: %w", Episodes, err)
+	}
+	medias, err := api.mediaAdapter.FindAll(ctx)
+	if err != nil {
+		return model.IntegrityReport{}, fmt.Errorf("could not find all %s: %w", Medias, err)
+	}
+
+	categoryIDs := make(map[string]struct{}, len(categories))
+	for _, category := range categories {
+		categoryIDs[category.ID] = struct{}{}
+	}
+	programIDs := make(map[string]struct{}, len(programs))
+	for _, program := range programs {
+		programIDs[program.ID] = struct{}{}
+	}
+	episodeIDs := make(map[string]struct{}, len(episodes))
+	programsWithEpisodes := make(map[string]struct{})
+	for _, episode := range episodes {
+		episodeIDs[episode.ID] = struct{}{}
+		programsWithEpisodes[episode.ProgramID] = struct{}{}
+	}
+
+	episodesCheck := model.IntegrityCheck{Name: model.IntegrityEpisodeWithoutProgram, DocsType: Episodes, Checked: len(episodes), Orphans: []model.Orphan{}}
+	for _, episode := range episodes {
+		if _, ok := programIDs[episode.ProgramID]; !ok {
+			episodesCheck.Orphans = append(episodesCheck.Orphans, model.Orphan{ID: episode.ID, ReferenceID: episode.ProgramID})
+		}
+	}
+	mediasCheck := model.IntegrityCheck{Name: model.IntegrityMediaWithoutEpisode, DocsType: Medias, Checked: len(medias), Orphans: []model.Orphan{}}
+	for _, media := range medias {
+		if _, ok := episodeIDs[media.EpisodeID]; !ok {
+			mediasCheck.Orphans = append(mediasCheck.Orphans, model.Orphan{ID: media.ID, ReferenceID: media.EpisodeID})
+		}
+	}
+	categoriesCheck := model.IntegrityCheck{Name: model.IntegrityCategoryWithoutParent, DocsType: Cats, Checked: len(categories), Orphans: []model.Orphan{}}
+	for _, category := range categories {
+		parentID := parentCategoryID(category)
+		if parentID == "" {
+			continue
+		}
+		if _, ok := categoryIDs[parentID]; !ok {
+			categoriesCheck.Orphans = append(categoriesCheck.Orphans, model.Orphan{ID: category.ID, ReferenceID: parentID})
+		}
+	}
+	programsCheck := model.IntegrityCheck{Name: model.IntegrityProgramWithoutEpisodes, DocsType: Programs, Checked: len(programs), Orphans: []model.Orphan{}}
+	for _, program := range programs {
+		if _, ok := programsWithEpisodes[program.ID]; !ok {
+			programsCheck.Orphans = append(programsCheck.Orphans, model.Orphan{ID: program.ID})
+		}
+	}
+
+	return model.IntegrityReport{
+		Checks:    []model.IntegrityCheck{episodesCheck, mediasCheck, categoriesCheck, programsCheck},
+		CheckedAt: checkedAt,
+	}, nil
+}
+
+// parentCategoryID returns the identifier of the parent of the category, or an empty string for a root category.
+// Root categories are read with a parent holding the nil UUID.
+func parentCategoryID(category *model.Category) string {
+	if category.Parent == nil || category.Parent.ID == uuid.Nil.String() {
+		return ""
+	}
+	return category.Parent.ID
+}
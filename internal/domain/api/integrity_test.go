@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// fakeCategoryAdapter is a port.Category returning fixed categories from FindAll.
+type fakeCategoryAdapter struct {
+	port.Category
+	categories []*model.Category
+}
+
+func (f fakeCategoryAdapter) FindAll(ctx context.Context) ([]*model.Category, error) {
+	return f.categories, nil
+}
+
+// fakeEpisodeAdapter is a port.Episode returning fixed episodes.
+type fakeEpisodeAdapter struct {
+	port.Episode
+	episodes []*model.Episode
+}
+
+func (f fakeEpisodeAdapter) FindAll(ctx context.Context) ([]*model.Episode, error) {
+	return f.episodes, nil
+}
+
+func (f fakeEpisodeAdapter) Find(ctx context.Context, id string) (*model.Episode, error) {
+	for _, episode := range f.episodes {
+		if episode.ID == id {
+			return episode, nil
+		}
+	}
+	return nil, port.ErrNotFound
+}
+
+func (f fakeEpisodeAdapter) CountByProgram(ctx context.Context, programIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, episode := range f.episodes {
+		for _, programID := range programIDs {
+			if episode.ProgramID == programID {
+				counts[programID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// fakeMediaAdapter is a port.Media returning fixed medias from FindAll.
+type fakeMediaAdapter struct {
+	port.Media
+	medias []*model.Media
+}
+
+func (f fakeMediaAdapter) FindAll(ctx context.Context) ([]*model.Media, error) {
+	return f.medias, nil
+}
+
+func Test_Integrity_WhenReferencesAreBroken(t *testing.T) {
+	indexerApi := NewIndexerApi(newFakeIndexer(), nil,
+		fakeCategoryAdapter{categories: []*model.Category{
+			{ID: "c1", Parent: &model.Category{ID: "00000000-0000-0000-0000-000000000000"}},
+			{ID: "c2", Parent: &model.Category{ID: "c1"}},
+			{ID: "c3", Parent: &model.Category{ID: "c9"}},
+		}},
+		nil, nil,
+		fakeProgramAdapter{programs: []*model.Program{{ID: "p1"}, {ID: "p2"}}},
+		fakeEpisodeAdapter{episodes: []*model.Episode{{ID: "e1", ProgramID: "p1"}, {ID: "e2", ProgramID: "p9"}}},
+		fakeMediaAdapter{medias: []*model.Media{{ID: "m1", EpisodeID: "e1"}, {ID: "m2"}}},
+	)
+
+	report, err := indexerApi.Integrity(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, report.Consistent())
+	assert.Equal(t, []model.IntegrityCheck{
+		{Name: model.IntegrityEpisodeWithoutProgram, DocsType: Episodes, Checked: 2, Orphans: []model.Orphan{{ID: "e2", ReferenceID: "p9"}}},
+		{Name: model.IntegrityMediaWithoutEpisode, DocsType: Medias, Checked: 2, Orphans: []model.Orphan{{ID: "m2"}}},
+		{Name: model.IntegrityCategoryWithoutParent, DocsType: Cats, Checked: 3, Orphans: []model.Orphan{{ID: "c3", ReferenceID: "c9"}}},
+		{Name: model.IntegrityProgramWithoutEpisodes, DocsType: Programs, Checked: 2, Orphans: []model.Orphan{{ID: "p2"}}},
+	}, report.Checks)
+}
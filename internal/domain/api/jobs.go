@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxFinishedJobs is the number of finished jobs kept in memory.
+const maxFinishedJobs = 100
+
+// jobLeasePrefix prefixes the name of the lease of each docs type, held by the replica running its job.
+const jobLeasePrefix = "indexation-job-"
+
+var (
+	// ErrJobNotFound is returned when no job has the requested identifier.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when cancelling a job that is no longer running.
+	ErrJobFinished = errors.New("job already finished")
+	// ErrJobInProgress is returned when starting a job for a docs type that is already being indexed,
+	// by this instance or by another replica sharing the job leases.
+	ErrJobInProgress = errors.New("an indexation of this docs type is already in progress")
+	// ErrUnsupportedMode is returned when the docs type cannot be indexed with the requested mode.
+	ErrUnsupportedMode = errors.New("unsupported docs type or mode")
+)
+
+// JobRequest describes an indexation to run as a background job.
+type JobRequest struct {
+	DocsType string    // Docs type to index
+	Mode     string    // Indexation mode (full, delta or reconcile)
+	Trigger  string    // Source that triggered the indexation
+	Since    time.Time // Lower bound of the documents written by a delta indexation
+}
+
+// Start runs the requested indexation in the background and returns the started job.
+// The job has its own root context with the deadline of its docs type, and keeps the trace identifier
+// and logger of the given context. The job is recorded in the history, if any, with the caller carried by
+// the given context when it starts and when it finishes, and its outcome is published to the notifiers.
+// Only one job per docs type runs at a time: if one is already running, it is returned with ErrJobInProgress.
+// With job leases, the rule holds across the replicas: a job running on another replica is not known here,
+// only its docs type is returned along with ErrJobInProgress.
+func (api indexerApi) Start(ctx context.Context, request JobRequest) (model.Job, error) {
+	if !SupportsMode(request.DocsType, request.Mode) {
+		return model.Job{}, ErrUnsupportedMode
+	}
+
+	caller := Caller(ctx)
+	ctx, cancel := api.jobContext(ctx, request.DocsType)
+	job, err := api.jobs.start(request, caller, cancel)
+	if err != nil {
+		cancel()
+		return job, err
+	}
+	unlock, err := api.lockJob(ctx, request.DocsType, cancel)
+	if err != nil {
+		api.jobs.discard(job.ID)
+		cancel()
+		if errors.Is(err, ErrJobInProgress) {
+			return model.Job{DocsType: request.DocsType, Status: model.JobRunning}, err
+		}
+		return model.Job{}, err
+	}
+
+	go func() {
+		defer cancel()
+		logger := log.Ctx(ctx).With().Str("job", job.ID).Str("docsType", job.DocsType).Str("mode", job.Mode).Logger()
+		ctx = withProgress(logger.WithContext(ctx), api.jobs, job.ID)
+		logger.Info().Msg("indexation started")
+		api.save(ctx, job)
+
+		err := api.run(ctx, request)
+		// The lease is released before the job is finished, so that it never releases the lease of the next job
+		unlock()
+		finished := api.jobs.finish(job.ID, err, ctx.Err())
+		api.save(ctx, finished)
+		api.notifyOutcome(ctx, finished)
+		api.jobs.release(job.ID)
+
+		switch finished.Status {
+		case model.JobSucceeded:
+			logger.Info().Msg("indexation finished")
+		case model.JobCancelled:
+			logger.Warn().Msg("indexation cancelled")
+		case model.JobFailed:
+			if finished.Reason == model.ReasonTimeout {
+				logger.Error().Err(err).Msg("indexation timed out")
+				break
+			}
+			logger.Error().Err(err).Msg("indexation failed")
+		default:
+			logger.Error().Err(err).Msg("indexation failed")
+		}
+	}()
+	return job, nil
+}
+
+// Job returns the job with the given identifier.
+func (api indexerApi) Job(id string) (model.Job, error) {
+	return api.jobs.get(id)
+}
+
+// Jobs returns the running jobs and the most recently finished ones, newest first.
+func (api indexerApi) Jobs() []model.Job {
+	return api.jobs.list()
+}
+
+// Cancel cancels the context of a running job. The job ends in the cancelled state
+// once the indexation has stopped and its partial index has been deleted.
+func (api indexerApi) Cancel(id string) (model.Job, error) {
+	return api.jobs.cancel(id)
+}
+
+// Wait blocks until the job with the given identifier is finished and recorded in the history, or the context is done.
+func (api indexerApi) Wait(ctx context.Context, id string) (model.Job, error) {
+	done, err := api.jobs.done(id)
+	if err != nil {
+		return model.Job{}, err
+	}
+	select {
+	case <-done:
+		return api.jobs.get(id)
+	case <-ctx.Done():
+		return model.Job{}, ctx.Err()
+	}
+}
+
+// lockJob takes the lease of the docs type, if job leases are configured, and renews it until the returned function
+// is called, which releases it. The job is cancelled through the given function as soon as the lease is lost.
+// It fails with ErrJobInProgress if another replica holds the lease.
+func (api indexerApi) lockJob(ctx context.Context, docsType string, cancel context.CancelFunc) (func(), error) {
+	if api.jobLease == nil {
+		return func() {}, nil
+	}
+	name := jobLeasePrefix + docsType
+	acquired, err := api.jobLease.Acquire(ctx, name, api.leaseHolder, api.leaseTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrJobInProgress
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(api.leaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			acquired, err := api.jobLease.Acquire(ctx, name, api.leaseHolder, api.leaseTTL)
+			if err != nil || !acquired {
+				log.Ctx(ctx).Error().Err(err).Str("lease", name).Msg("job lease lost, indexation cancelled")
+				cancel()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelRelease()
+		if err := api.jobLease.Release(releaseCtx, name, api.leaseHolder); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("lease", name).Msg("could not release job lease")
+		}
+	}, nil
+}
+
+// run executes the requested indexation synchronously.
+func (api indexerApi) run(ctx context.Context, request JobRequest) error {
+	switch request.Mode {
+	case ModeDelta:
+		return api.Delta(ctx, request.DocsType, request.Since)
+	case ModeReconcile:
+		return api.Reconcile(ctx, request.DocsType)
+	default:
+		return api.index(ctx, request.DocsType)
+	}
+}
+
+// jobRegistry keeps track of the running jobs and of the most recently finished ones.
+type jobRegistry struct {
+	mu       sync.RWMutex
+	jobs     map[string]*trackedJob
+	finished []string // Identifiers of the finished jobs, oldest first
+}
+
+// trackedJob is a job along with the means to cancel it and to wait for it.
+type trackedJob struct {
+	job         model.Job
+	cancel      context.CancelFunc
+	done        chan struct{}
+	subscribers []chan model.JobEvent
+}
+
+// newJobRegistry returns an empty job registry.
+func newJobRegistry() *jobRegistry {
+	return &jobRegistry{jobs: make(map[string]*trackedJob)}
+}
+
+// start registers a new running job, unless a job for the same docs type is already running.
+func (r *jobRegistry) start(request JobRequest, caller string, cancel context.CancelFunc) (model.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, tracked := range r.jobs {
+		if tracked.job.DocsType == request.DocsType && !tracked.job.Finished() {
+			return tracked.job, ErrJobInProgress
+		}
+	}
+
+	job := model.Job{
+		ID:        uuid.NewString(),
+		DocsType:  request.DocsType,
+		Mode:      request.Mode,
+		Trigger:   request.Trigger,
+		Caller:    caller,
+		Status:    model.JobRunning,
+		StartedAt: time.Now(),
+	}
+	r.jobs[job.ID] = &trackedJob{job: job, cancel: cancel, done: make(chan struct{})}
+	return job, nil
+}
+
+// discard forgets a job that could not be started.
+func (r *jobRegistry) discard(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.jobs, id)
+}
+
+// finish records the outcome of a job and notifies its subscribers. A job stopped by an error after its context
+// was cancelled is cancelled, and one stopped after its deadline was exceeded is failed with the timeout reason.
+// The callers waiting for the job are only released by release.
+func (r *jobRegistry) finish(id string, err, ctxErr error) model.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracked := r.jobs[id]
+	finishedAt := time.Now()
+	tracked.job.FinishedAt = &finishedAt
+	switch {
+	case err == nil:
+		tracked.job.Status = model.JobSucceeded
+	case errors.Is(ctxErr, context.Canceled):
+		tracked.job.Status = model.JobCancelled
+		tracked.job.Error = err.Error()
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		tracked.job.Status = model.JobFailed
+		tracked.job.Reason = model.ReasonTimeout
+		tracked.job.Error = err.Error()
+	default:
+		tracked.job.Status = model.JobFailed
+		tracked.job.Reason = model.ReasonError
+		tracked.job.Error = err.Error()
+	}
+	tracked.closeSubscribers()
+	return tracked.job
+}
+
+// release closes the channel of a finished job, releasing the callers waiting for it,
+// and forgets the oldest finished jobs.
+func (r *jobRegistry) release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	close(r.jobs[id].done)
+	r.finished = append(r.finished, id)
+	for len(r.finished) > maxFinishedJobs {
+		delete(r.jobs, r.finished[0])
+		r.finished = r.finished[1:]
+	}
+}
+
+// get returns the job with the given identifier.
+func (r *jobRegistry) get(id string) (model.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tracked, ok := r.jobs[id]
+	if !ok {
+		return model.Job{}, ErrJobNotFound
+	}
+	return tracked.job, nil
+}
+
+// done returns a channel closed when the job with the given identifier is finished.
+func (r *jobRegistry) done(id string) (<-chan struct{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tracked, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return tracked.done, nil
+}
+
+// list returns the known jobs, newest first.
+func (r *jobRegistry) list() []model.Job {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := make([]model.Job, 0, len(r.jobs))
+	for _, tracked := range r.jobs {
+		jobs = append(jobs, tracked.job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.After(jobs[j].StartedAt)
+	})
+	return jobs
+}
+
+// cancel cancels the context of a running job.
+func (r *jobRegistry) cancel(id string) (model.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tracked, ok := r.jobs[id]
+	if !ok {
+		return model.Job{}, ErrJobNotFound
+	}
+	if tracked.job.Finished() {
+		return tracked.job, ErrJobFinished
+	}
+	tracked.cancel()
+	return tracked.job, nil
+}
@@ -0,0 +1,547 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/khedhrije/podcaster-indexer-api/internal/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIndexer is an in-memory port.Indexer recording the indexes and aliases it manages,
+// along with the search terms of each index. Creating the failAlias alias fails, if set.
+// Like the search engine, it overwrites the documents with the same identifier and versions them: a write with
+// an external version not greater than the indexed one is skipped as stale, other writes increment the version.
+type fakeIndexer struct {
+	mu        sync.Mutex
+	indexes   map[string][]interface{}
+	versions  map[string]map[string]int64
+	aliases   map[string]map[string]bool
+	terms     map[string]model.SearchTerms
+	failAlias string
+}
+
+func newFakeIndexer() *fakeIndexer {
+	return &fakeIndexer{
+		indexes:  map[string][]interface{}{},
+		versions: map[string]map[string]int64{},
+		aliases:  map[string]map[string]bool{},
+		terms:    map[string]model.SearchTerms{},
+	}
+}
+
+func (f *fakeIndexer) CreateIndex(ctx context.Context, indexName string, docsType string, terms model.SearchTerms) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.indexes[indexName] = nil
+	f.terms[indexName] = terms
+	return nil
+}
+
+func (f *fakeIndexer) ReloadSearchTerms(ctx context.Context, indexName string, docsType string, terms model.SearchTerms) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.terms[indexName] = terms
+	return nil
+}
+
+func (f *fakeIndexer) DeleteIndexes(ctx context.Context, indexNames []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, name := range indexNames {
+		delete(f.indexes, name)
+		for _, indexes := range f.aliases {
+			delete(indexes, name)
+		}
+	}
+	return nil
+}
+
+func (f *fakeIndexer) CreateAlias(ctx context.Context, indexName, aliasName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if aliasName == f.failAlias {
+		return errors.New("alias creation failed")
+	}
+	if f.aliases[aliasName] == nil {
+		f.aliases[aliasName] = map[string]bool{}
+	}
+	f.aliases[aliasName][indexName] = true
+	return nil
+}
+
+func (f *fakeIndexer) DeleteAlias(ctx context.Context, indexName, aliasName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.aliases[aliasName], indexName)
+	return nil
+}
+
+func (f *fakeIndexer) IndexByAlias(ctx context.Context, aliasName string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name := range f.aliases[aliasName] {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (f *fakeIndexer) MoveIndex(ctx context.Context, indexationName string) error {
+	return nil
+}
+
+func (f *fakeIndexer) DeleteDocument(ctx context.Context, indexName, documentID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.versions[indexName], documentID)
+	var kept []interface{}
+	for _, item := range f.indexes[indexName] {
+		if document, ok := item.(model.Document); !ok || document.DocumentID() != documentID {
+			kept = append(kept, item)
+		}
+	}
+	f.indexes[indexName] = kept
+	return nil
+}
+
+func (f *fakeIndexer) DeleteDocuments(ctx context.Context, indexName string, documentIDs []string) (int, error) {
+	removed := 0
+	for _, id := range documentIDs {
+		before := len(f.documents(indexName))
+		_ = f.DeleteDocument(ctx, indexName, id)
+		if len(f.documents(indexName)) < before {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (f *fakeIndexer) DocumentIDs(ctx context.Context, indexName string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []string
+	for _, item := range f.indexes[indexName] {
+		if document, ok := item.(model.Document); ok {
+			ids = append(ids, document.DocumentID())
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeIndexer) Documents(ctx context.Context, indexName string, documentIDs []string) (map[string][]byte, error) {
+	sources, err := f.DocumentSources(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string][]byte)
+	for _, id := range documentIDs {
+		if source, ok := sources[id]; ok {
+			found[id] = source
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeIndexer) DocumentSources(ctx context.Context, indexName string) (map[string][]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sources := make(map[string][]byte)
+	for _, item := range f.indexes[indexName] {
+		if document, ok := item.(model.Document); ok {
+			source, err := json.Marshal(item)
+			if err != nil {
+				return nil, err
+			}
+			sources[document.DocumentID()] = source
+		}
+	}
+	return sources, nil
+}
+
+func (f *fakeIndexer) RecordBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.bulk(ctx, indexName, items, true)
+}
+
+func (f *fakeIndexer) OverwriteBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.bulk(ctx, indexName, items, false)
+}
+
+func (f *fakeIndexer) bulk(ctx context.Context, indexName string, items []interface{}, external bool) (model.BulkResult, error) {
+	var result model.BulkResult
+	for _, item := range items {
+		if f.write(indexName, item, external) {
+			result.Indexed++
+		} else {
+			result.Stale++
+			result.NotWritten = append(result.NotWritten, documentID(item))
+		}
+	}
+	return result, ctx.Err()
+}
+
+// write indexes the item, replacing the document with the same identifier, unless its external version is checked
+// and not greater than the indexed one.
+func (f *fakeIndexer) write(indexName string, item interface{}, external bool) bool {
+	document, ok := item.(model.Document)
+	if !ok || document.DocumentID() == "" {
+		f.indexes[indexName] = append(f.indexes[indexName], item)
+		return true
+	}
+	if f.versions[indexName] == nil {
+		f.versions[indexName] = map[string]int64{}
+	}
+	id := document.DocumentID()
+	indexed, exists := f.versions[indexName][id]
+	version := indexed + 1
+	if versioned, ok := item.(model.Versioned); ok && external && versioned.DocumentVersion() > 0 {
+		if exists && versioned.DocumentVersion() <= indexed {
+			return false
+		}
+		version = versioned.DocumentVersion()
+	}
+	f.versions[indexName][id] = version
+	for i, existing := range f.indexes[indexName] {
+		if existing, ok := existing.(model.Document); ok && existing.DocumentID() == id {
+			f.indexes[indexName][i] = item
+			return true
+		}
+	}
+	f.indexes[indexName] = append(f.indexes[indexName], item)
+	return true
+}
+
+func (f *fakeIndexer) ValidateDocuments(ctx context.Context, docsType string, items []interface{}) ([]model.DocumentIssue, error) {
+	return nil, nil
+}
+
+func (f *fakeIndexer) documents(indexName string) []interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]interface{}(nil), f.indexes[indexName]...)
+}
+
+func (f *fakeIndexer) indexNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name := range f.indexes {
+		names = append(names, name)
+	}
+	return names
+}
+
+// fakeProgramAdapter is a port.Program returning fixed programs, once released if a release channel is set,
+// or blocking until its context is done.
+type fakeProgramAdapter struct {
+	programs []*model.Program
+	deleted  []string
+	block    bool
+	release  chan struct{}
+}
+
+func (f fakeProgramAdapter) FindAll(ctx context.Context) ([]*model.Program, error) {
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if f.release != nil {
+		<-f.release
+	}
+	return f.programs, nil
+}
+
+func (f fakeProgramAdapter) Find(ctx context.Context, id string) (*model.Program, error) {
+	for _, program := range f.programs {
+		if program.ID == id {
+			return program, nil
+		}
+	}
+	return nil, port.ErrNotFound
+}
+
+func (f fakeProgramAdapter) FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Program, error) {
+	return f.FindAll(ctx)
+}
+
+func (f fakeProgramAdapter) FindDeletedSince(ctx context.Context, since time.Time) ([]string, error) {
+	return f.deleted, nil
+}
+
+func (f fakeProgramAdapter) FindIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	for _, program := range f.programs {
+		ids = append(ids, program.ID)
+	}
+	return ids, nil
+}
+
+func (f fakeProgramAdapter) FindExistingIDs(ctx context.Context, ids []string) ([]string, error) {
+	var existing []string
+	for _, program := range f.programs {
+		for _, id := range ids {
+			if program.ID == id {
+				existing = append(existing, id)
+			}
+		}
+	}
+	return existing, nil
+}
+
+func newTestIndexerApi(indexer *fakeIndexer, programAdapter fakeProgramAdapter) Indexer {
+	return NewIndexerApi(indexer, nil, nil, nil, nil, programAdapter, nil, nil)
+}
+
+func Test_Start_WhenJobSucceeds(t *testing.T) {
+	indexer := newFakeIndexer()
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}})
+
+	job, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(context.Background(), job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, job.Status)
+	assert.Len(t, indexer.IndexByAlias(context.Background(), latestAlias), 1)
+}
+
+func Test_Start_WhenDocsTypeIsInProgress(t *testing.T) {
+	indexerApi := newTestIndexerApi(newFakeIndexer(), fakeProgramAdapter{block: true})
+
+	first, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	second, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+
+	assert.ErrorIs(t, err, ErrJobInProgress)
+	assert.Equal(t, first.ID, second.ID, "Should return the running job")
+	_, _ = indexerApi.Cancel(first.ID)
+}
+
+func Test_Start_WhenDocsTypeIsInProgressOnAnotherReplica(t *testing.T) {
+	lease := memory.NewLeaseAdapter()
+	first := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{block: true}, nil, nil,
+		WithJobLease(lease, "replica-1", time.Minute))
+	second := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}}, nil, nil,
+		WithJobLease(lease, "replica-2", time.Minute))
+
+	running, err := first.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	job, err := second.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.ErrorIs(t, err, ErrJobInProgress)
+	assert.Equal(t, Programs, job.DocsType)
+	assert.Empty(t, second.Jobs(), "Should forget the job that could not start")
+
+	_, _ = first.Cancel(running.ID)
+	_, err = first.Wait(context.Background(), running.ID)
+	assert.NoError(t, err)
+	job, err = second.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err, "Should start once the other replica released the lease")
+	job, err = second.Wait(context.Background(), job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, job.Status)
+}
+
+func Test_Cancel_WhenJobIsRunning(t *testing.T) {
+	indexer := newFakeIndexer()
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{block: true})
+
+	job, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	_, err = indexerApi.Cancel(job.ID)
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(context.Background(), job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobCancelled, job.Status)
+	assert.Empty(t, indexer.indexNames(), "Partial index should be deleted")
+
+	_, err = indexerApi.Cancel(job.ID)
+	assert.ErrorIs(t, err, ErrJobFinished)
+}
+
+func Test_Cancel_WhenJobIsUnknown(t *testing.T) {
+	indexerApi := newTestIndexerApi(newFakeIndexer(), fakeProgramAdapter{})
+
+	_, err := indexerApi.Cancel("unknown")
+
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func Test_Start_WhenJobTimesOut(t *testing.T) {
+	indexer := newFakeIndexer()
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil, fakeProgramAdapter{block: true}, nil, nil,
+		WithTimeouts(time.Hour, map[string]time.Duration{Programs: 10 * time.Millisecond}))
+
+	job, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(context.Background(), job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobFailed, job.Status)
+	assert.Equal(t, model.ReasonTimeout, job.Reason)
+	assert.Empty(t, indexer.indexNames(), "Partial index should be deleted")
+}
+
+func Test_Start_WhenCallerContextEnds(t *testing.T) {
+	indexerApi := newTestIndexerApi(newFakeIndexer(), fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}})
+	ctx, cancel := context.WithCancel(WithTraceID(context.Background(), "trace"))
+
+	job, err := indexerApi.Start(ctx, JobRequest{DocsType: Programs, Mode: ModeFull})
+	cancel()
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(context.Background(), job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, job.Status, "Job should not be bound to the caller's context")
+}
+
+func Test_Subscribe_WhenJobIsRunning(t *testing.T) {
+	release := make(chan struct{})
+	indexerApi := newTestIndexerApi(newFakeIndexer(), fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}, {ID: "p2", Name: "Program"}}, release: release})
+
+	job, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	events, unsubscribe, err := indexerApi.Subscribe(job.ID)
+	assert.NoError(t, err)
+	defer unsubscribe()
+	close(release)
+
+	var received []model.JobEvent
+	for event := range events {
+		received = append(received, event)
+	}
+
+	assert.Equal(t, model.EventSnapshot, received[0].Type, "Should first receive a snapshot")
+	last := received[len(received)-1]
+	assert.Equal(t, model.EventFinished, last.Type)
+	assert.Equal(t, model.JobSucceeded, last.Job.Status)
+	assert.Equal(t, model.PhasePromote, last.Job.Progress.Phase)
+	assert.Equal(t, 2, last.Job.Progress.RowsRead)
+	assert.Equal(t, 2, last.Job.Progress.Indexed)
+	assert.Equal(t, 1, last.Job.Progress.Flushes)
+}
+
+func Test_Subscribe_WhenJobIsFinished(t *testing.T) {
+	indexerApi := newTestIndexerApi(newFakeIndexer(), fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}})
+	job, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	_, err = indexerApi.Wait(context.Background(), job.ID)
+	assert.NoError(t, err)
+
+	events, unsubscribe, err := indexerApi.Subscribe(job.ID)
+	assert.NoError(t, err)
+	defer unsubscribe()
+
+	snapshot := <-events
+	assert.Equal(t, model.EventSnapshot, snapshot.Type)
+	assert.Equal(t, model.JobSucceeded, snapshot.Job.Status)
+	_, open := <-events
+	assert.False(t, open, "Should be closed after the snapshot of a finished job")
+}
+
+func Test_Subscribe_WhenSubscriberLagsBehind(t *testing.T) {
+	registry := newJobRegistry()
+	job, err := registry.start(JobRequest{DocsType: Programs, Mode: ModeFull}, "", func() {})
+	assert.NoError(t, err)
+	events, _, err := registry.subscribe(job.ID)
+	assert.NoError(t, err)
+	tracked := registry.jobs[job.ID]
+	for i := 0; i < subscriberBuffer; i++ {
+		tracked.notify(model.JobEvent{Type: model.EventProgress, Job: tracked.job})
+	}
+
+	registry.finish(job.ID, nil, nil)
+
+	var last model.JobEvent
+	count := 0
+	for event := range events {
+		last = event
+		count++
+	}
+	assert.Equal(t, subscriberBuffer, count)
+	assert.Equal(t, model.EventFinished, last.Type, "Should deliver the final event to a full subscriber")
+}
+
+func Test_Start_WhenHistoryIsConfigured(t *testing.T) {
+	history := memory.NewHistoryAdapter()
+	indexerApi := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}}, nil, nil,
+		WithHistory(history))
+
+	job, err := indexerApi.Start(WithCaller(context.Background(), "alice"), JobRequest{DocsType: Programs, Mode: ModeFull, Trigger: model.TriggerAPI})
+	assert.NoError(t, err)
+	_, err = indexerApi.Wait(context.Background(), job.ID)
+	assert.NoError(t, err)
+	page, err := indexerApi.History(context.Background(), model.JobFilter{DocsType: Programs, Caller: "alice"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, DefaultHistoryLimit, page.Limit)
+	recorded := page.Jobs[0]
+	assert.Equal(t, job.ID, recorded.ID)
+	assert.Equal(t, model.JobSucceeded, recorded.Status, "Should record the outcome before releasing waiters")
+	assert.Equal(t, model.TriggerAPI, recorded.Trigger)
+	assert.Contains(t, recorded.IndexName, Programs+"-")
+	assert.Equal(t, 1, recorded.Progress.Indexed)
+}
+
+func Test_History_WhenNotConfigured(t *testing.T) {
+	indexerApi := newTestIndexerApi(newFakeIndexer(), fakeProgramAdapter{})
+
+	_, err := indexerApi.History(context.Background(), model.JobFilter{})
+
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
+
+func Test_Start_WhenIndexIsPromoted(t *testing.T) {
+	publisher := memory.NewPublisher()
+	indexerApi := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}}, nil, nil,
+		WithPublishers(publisher))
+
+	job, err := indexerApi.Start(context.Background(), JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(context.Background(), job.ID)
+	assert.NoError(t, err)
+
+	events := publisher.Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, model.IndexEventPromoted, events[0].Type)
+	assert.Equal(t, model.IndexEventVersion, events[0].Version)
+	assert.Equal(t, job.ID, events[0].Data.JobID)
+	assert.Equal(t, job.IndexName, events[0].Data.LatestIndexName)
+	assert.Equal(t, 1, events[0].Data.Documents)
+}
+
+func Test_Start_WhenPromotionFails(t *testing.T) {
+	indexer := newFakeIndexer()
+	ctx := context.Background()
+	_ = indexer.CreateIndex(ctx, "programs-1", Programs, model.SearchTerms{})
+	_ = indexer.CreateAlias(ctx, "programs-1", latestAlias)
+	indexer.failAlias = previousAlias
+	publisher := memory.NewPublisher()
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}}, nil, nil,
+		WithPublishers(publisher))
+
+	job, err := indexerApi.Start(ctx, JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	job, err = indexerApi.Wait(ctx, job.ID)
+	assert.NoError(t, err)
+
+	assert.Equal(t, model.JobFailed, job.Status)
+	assert.Equal(t, []string{"programs-1"}, indexer.IndexByAlias(ctx, latestAlias), "Latest alias should be rolled back")
+	assert.Equal(t, []string{"programs-1"}, indexer.indexNames(), "New index should be deleted")
+	events := publisher.Events()
+	assert.Len(t, events, 2)
+	assert.Equal(t, model.IndexEventRolledBack, events[0].Type)
+	assert.Equal(t, "programs-1", events[0].Data.LatestIndexName)
+	assert.Equal(t, model.IndexEventFailed, events[1].Type)
+	assert.NotEmpty(t, events[1].Data.Error)
+}
@@ -2,101 +2,9 @@ package api
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
 )
 
 // Medias handles the indexing of medias
 func (api indexerApi) Medias(ctx context.Context) error {
-	// Initialize indexName with current Unix timestamp
-	indexName := fmt.Sprintf("medias-%v", time.Now().Unix())
-
-	// Create a new index
-	if err := api.indexer.CreateIndex(ctx, indexName, Medias); err != nil {
-		return err
-	}
-
-	// Assign the in-progress alias to the new index
-	if err := api.indexer.CreateAlias(ctx, indexName, "in-progress"); err != nil {
-		return err
-	}
-
-	// Retrieve all medias
-	medias, err := api.mediaAdapter.FindAll(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Bulk index medias
-	if err := api.indexer.RecordBulkItems(ctx, indexName, mapArrayToInterface(medias), 5, 5); err != nil {
-		return err
-	}
-
-	// Get all indexes associated with the latest alias
-	latestIndexesNames := api.indexer.IndexByAlias(ctx, "latest")
-
-	// Find the latest index name for medias
-	var mediasLatestIndexName string
-	for _, latestIndexName := range latestIndexesNames {
-		if strings.Contains(latestIndexName, "medias") {
-			mediasLatestIndexName = latestIndexName
-		}
-	}
-
-	// If there is no latest medias index
-	if mediasLatestIndexName == "" {
-		// Make the in-progress index the latest index
-		if err := api.indexer.DeleteAlias(ctx, indexName, "in-progress"); err != nil {
-			return err
-		}
-		if err := api.indexer.CreateAlias(ctx, indexName, "latest"); err != nil {
-			return err
-		}
-	} else {
-		// Handle existing latest index
-		previousIndexesNames := api.indexer.IndexByAlias(ctx, "previous")
-
-		// Find the previous index name for medias
-		var mediasPreviousIndexName string
-		for _, iName := range previousIndexesNames {
-			if strings.Contains(iName, "medias") {
-				mediasPreviousIndexName = iName
-			}
-		}
-
-		if mediasPreviousIndexName == "" {
-			// Make the latest index the previous index
-			if err := api.indexer.DeleteAlias(ctx, mediasLatestIndexName, "latest"); err != nil {
-				return err
-			}
-			if err := api.indexer.CreateAlias(ctx, mediasLatestIndexName, "previous"); err != nil {
-				return err
-			}
-		} else {
-			// Delete the previous index
-			if err := api.indexer.DeleteIndexes(ctx, []string{mediasPreviousIndexName}); err != nil {
-				return err
-			}
-			// Update aliases accordingly
-			if err := api.indexer.DeleteAlias(ctx, mediasLatestIndexName, "latest"); err != nil {
-				return err
-			}
-			if err := api.indexer.CreateAlias(ctx, mediasLatestIndexName, "previous"); err != nil {
-				return err
-			}
-		}
-
-		// Always remove the in-progress alias from the new index
-		if err := api.indexer.DeleteAlias(ctx, indexName, "in-progress"); err != nil {
-			return err
-		}
-
-		// Create latest alias for the new index
-		if err := api.indexer.CreateAlias(ctx, indexName, "latest"); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return api.index(ctx, Medias)
 }
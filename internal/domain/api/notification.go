@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// notifyOutcome publishes the notifications of a finished job to the notifiers, if any.
+// A successful full indexation is also notified as a promotion; cancelled jobs are not notified.
+func (api indexerApi) notifyOutcome(ctx context.Context, job model.Job) {
+	switch {
+	case job.Status == model.JobSucceeded && job.Mode == ModeFull:
+		api.notify(ctx, newNotification(model.NotificationPromoted, job))
+		api.notify(ctx, newNotification(model.NotificationSucceeded, job))
+	case job.Status == model.JobSucceeded:
+		api.notify(ctx, newNotification(model.NotificationSucceeded, job))
+	case job.Status == model.JobFailed:
+		api.notify(ctx, newNotification(model.NotificationFailed, job))
+	}
+}
+
+// notify publishes a notification to every notifier. A failure is logged without failing the job.
+func (api indexerApi) notify(ctx context.Context, notification model.Notification) {
+	for _, notifier := range api.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("notification", notification.Type).Msg("could not publish notification")
+		}
+	}
+}
+
+// newNotification returns a notification of the given type describing the job.
+func newNotification(notificationType string, job model.Job) model.Notification {
+	notification := model.Notification{
+		ID:         uuid.NewString(),
+		Type:       notificationType,
+		OccurredAt: time.Now(),
+		JobID:      job.ID,
+		DocsType:   job.DocsType,
+		Mode:       job.Mode,
+		Trigger:    job.Trigger,
+		IndexName:  job.IndexName,
+		Status:     job.Status,
+		Reason:     job.Reason,
+		Error:      job.Error,
+		Counts: model.NotificationCounts{
+			Documents: job.Progress.Documents,
+			Invalid:   job.Progress.Invalid,
+			Dropped:   job.Progress.Dropped,
+			Indexed:   job.Progress.Indexed,
+			Failures:  job.Progress.Failures,
+			Removed:   job.Progress.Removed,
+			Stale:     job.Progress.Stale,
+		},
+	}
+	if job.FinishedAt != nil {
+		notification.DurationMs = job.FinishedAt.Sub(job.StartedAt).Milliseconds()
+	}
+	return notification
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// Option configures optional behaviours of the indexer api.
+type Option func(*indexerApi)
+
+// WithTimeouts sets the deadline of the indexation jobs: a deadline per docs type,
+// and a default one for the other docs types. A zero duration means no deadline.
+func WithTimeouts(defaultTimeout time.Duration, timeouts map[string]time.Duration) Option {
+	return func(api *indexerApi) {
+		api.defaultTimeout = defaultTimeout
+		api.timeouts = timeouts
+	}
+}
+
+// WithQualitySeverities overrides the severity of the built-in data-quality rules, per rule name.
+// Unknown severities are ignored, leaving the built-in severity of the rule.
+func WithQualitySeverities(severities map[string]string) Option {
+	return func(api *indexerApi) {
+		api.severities = severities
+	}
+}
+
+// WithEnrichers registers enrichers run in order on the documents of the docs type, after the ones already registered.
+// The documents an enricher fails to enrich are handled according to onError: model.SeverityWarn indexes them as is,
+// model.SeverityDrop leaves them out and model.SeverityFail fails the run.
+func WithEnrichers(docsType, onError string, enrichers ...Enricher) Option {
+	return func(api *indexerApi) {
+		for _, enricher := range enrichers {
+			api.enrichers[docsType] = append(api.enrichers[docsType], enrichment{enricher: enricher, onError: onError})
+		}
+	}
+}
+
+// WithBuiltinEnrichers registers the built-in enrichers named per docs type, in order. A name may be followed by
+// the handling of the errors of the enricher, e.g. "episode-count=fail", which defaults to model.SeverityWarn.
+// Unknown names, names of enrichers not supporting the docs type and unknown error handlings are ignored.
+func WithBuiltinEnrichers(enrichers map[string][]string) Option {
+	return func(api *indexerApi) {
+		for docsType, names := range enrichers {
+			for _, entry := range names {
+				name, onError, _ := strings.Cut(entry, "=")
+				if onError == "" {
+					onError = model.SeverityWarn
+				}
+				enricher, ok := api.builtinEnricher(name, docsType)
+				if !ok || (onError != model.SeverityWarn && onError != model.SeverityDrop && onError != model.SeverityFail) {
+					log.Warn().Str("docsType", docsType).Str("enricher", entry).Msg("unknown enricher ignored")
+					continue
+				}
+				api.enrichers[docsType] = append(api.enrichers[docsType], enrichment{enricher: enricher, onError: onError})
+			}
+		}
+	}
+}
+
+// WithTextNormalization sets how the normalize enricher summarizes the descriptions: the maximum length of the summaries,
+// none being set if it is not positive, and whether the raw descriptions are kept along with the normalized ones.
+// It applies to the normalize enrichers registered after it.
+func WithTextNormalization(summaryLength int, keepRawDescription bool) Option {
+	return func(api *indexerApi) {
+		api.summaryLength = summaryLength
+		api.keepRawDescription = keepRawDescription
+	}
+}
+
+// WithLanguages sets the languages of the localized fields per docs type, and whether the languages enricher detects
+// the language of each document to localize it in that language only. It applies to the languages enrichers
+// registered after it.
+func WithLanguages(languages map[string][]string, detect bool) Option {
+	return func(api *indexerApi) {
+		api.languages = languages
+		api.detectLanguage = detect
+	}
+}
+
+// WithHistory records every indexation job in the given history.
+func WithHistory(history port.History) Option {
+	return func(api *indexerApi) {
+		api.history = history
+	}
+}
+
+// WithJobLease runs a job only while holding the lease of its docs type, so that replicas sharing the leases
+// never index the same docs type at the same time. The lease is held by the given holder and renewed at a third of its TTL.
+// A non-positive TTL, which could not be renewed, leaves the jobs without lease.
+func WithJobLease(lease port.Lease, holder string, ttl time.Duration) Option {
+	return func(api *indexerApi) {
+		if ttl <= 0 {
+			return
+		}
+		api.jobLease = lease
+		api.leaseHolder = holder
+		api.leaseTTL = ttl
+	}
+}
+
+// WithSearchTerms manages the synonyms and stopwords applied by the analyzers in the given store.
+func WithSearchTerms(store port.SearchTerms) Option {
+	return func(api *indexerApi) {
+		api.searchTerms = store
+	}
+}
+
+// WithNotifiers publishes the outcome of the indexation jobs to the given notifiers.
+func WithNotifiers(notifiers ...port.Notifier) Option {
+	return func(api *indexerApi) {
+		api.notifiers = append(api.notifiers, notifiers...)
+	}
+}
+
+// WithPublishers publishes the events of the rotation of the indexes to the given publishers.
+func WithPublishers(publishers ...port.Publisher) Option {
+	return func(api *indexerApi) {
+		api.publishers = append(api.publishers, publishers...)
+	}
+}
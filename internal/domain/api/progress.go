@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// subscriberBuffer is the number of events buffered per subscriber before events are dropped.
+// Every event carries the whole job state, so a dropped event is made up for by the next one, and the final event
+// is never dropped.
+const subscriberBuffer = 64
+
+// progressKey is the context key of the progress reporter of the running job.
+type progressKey struct{}
+
+// progressReporter records the progress of the job running with a context.
+type progressReporter struct {
+	registry *jobRegistry // Registry of the job, nil when the progress is not reported, see withoutProgress
+	jobID    string
+}
+
+// withProgress returns a copy of the context reporting the progress of the pipeline to the given job.
+func withProgress(ctx context.Context, registry *jobRegistry, jobID string) context.Context {
+	return context.WithValue(ctx, progressKey{}, progressReporter{registry: registry, jobID: jobID})
+}
+
+// jobID returns the identifier of the job running with the context, or an empty string.
+func jobID(ctx context.Context) string {
+	reporter, _ := ctx.Value(progressKey{}).(progressReporter)
+	return reporter.jobID
+}
+
+// reporterOf returns the progress reporter of the job running with the context, if it reports to the job.
+func reporterOf(ctx context.Context) (progressReporter, bool) {
+	reporter, ok := ctx.Value(progressKey{}).(progressReporter)
+	return reporter, ok && reporter.registry != nil
+}
+
+// reportPhase records that the job running with the context entered a new phase.
+// It does nothing when the context does not belong to a job, e.g. for dry runs.
+func reportPhase(ctx context.Context, phase string) {
+	if reporter, ok := reporterOf(ctx); ok {
+		reporter.registry.update(reporter.jobID, model.EventPhase, func(job *model.Job) {
+			job.Progress.Phase = phase
+		})
+	}
+}
+
+// reportProgress updates the counters of the job running with the context.
+// It does nothing when the context does not belong to a job, e.g. for dry runs.
+func reportProgress(ctx context.Context, update func(progress *model.Progress)) {
+	if reporter, ok := reporterOf(ctx); ok {
+		reporter.registry.update(reporter.jobID, model.EventProgress, func(job *model.Job) {
+			update(&job.Progress)
+		})
+	}
+}
+
+// reportIndexName records the name of the index written by the job running with the context.
+// It does nothing when the context does not belong to a job, e.g. for dry runs.
+func reportIndexName(ctx context.Context, indexName string) {
+	if reporter, ok := reporterOf(ctx); ok {
+		reporter.registry.update(reporter.jobID, model.EventProgress, func(job *model.Job) {
+			job.IndexName = indexName
+		})
+	}
+}
+
+// reportQuality adds the data-quality violations of the documents of the job running with the context to those already
+// reported, merging the violations of a same rule, and counts the documents dropped because of them along with those
+// dropped by the enrichment.
+// It does nothing when the context does not belong to a job, e.g. for dry runs.
+func reportQuality(ctx context.Context, violations []model.QualityViolation, dropped int) {
+	if reporter, ok := reporterOf(ctx); ok {
+		reporter.registry.update(reporter.jobID, model.EventProgress, func(job *model.Job) {
+			for _, violation := range violations {
+				job.Quality = mergeViolation(job.Quality, violation)
+			}
+			job.Progress.Dropped += dropped
+		})
+	}
+}
+
+// mergeViolation adds a violation to the reported ones, appending its entities to the violation of the same rule if any.
+func mergeViolation(reported []model.QualityViolation, violation model.QualityViolation) []model.QualityViolation {
+	for i := range reported {
+		if reported[i].Rule == violation.Rule {
+			reported[i].EntityIDs = append(reported[i].EntityIDs, violation.EntityIDs...)
+			return reported
+		}
+	}
+	return append(reported, violation)
+}
+
+// withoutProgress returns a copy of the context that still belongs to the job running with it but no longer reports
+// its progress, for the writes a job triggers on other documents, e.g. the programs refreshed after their episodes,
+// whose violations and dropped documents are not those of the job.
+func withoutProgress(ctx context.Context) context.Context {
+	return context.WithValue(ctx, progressKey{}, progressReporter{jobID: jobID(ctx)})
+}
+
+// Subscribe returns the events of the job with the given identifier, starting with a snapshot of its current state.
+// The channel is closed once the job is finished; the returned function must be called to stop receiving events.
+func (api indexerApi) Subscribe(id string) (<-chan model.JobEvent, func(), error) {
+	return api.jobs.subscribe(id)
+}
+
+// update applies a change to a running job and notifies its subscribers.
+func (r *jobRegistry) update(id, eventType string, change func(job *model.Job)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracked, ok := r.jobs[id]
+	if !ok {
+		return
+	}
+	change(&tracked.job)
+	tracked.notify(model.JobEvent{Type: eventType, Job: tracked.job})
+}
+
+// subscribe registers a new subscriber to the events of a job.
+func (r *jobRegistry) subscribe(id string) (<-chan model.JobEvent, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracked, ok := r.jobs[id]
+	if !ok {
+		return nil, nil, ErrJobNotFound
+	}
+
+	events := make(chan model.JobEvent, subscriberBuffer)
+	events <- model.JobEvent{Type: model.EventSnapshot, Job: tracked.job}
+	if tracked.job.Finished() {
+		close(events)
+		return events, func() {}, nil
+	}
+
+	tracked.subscribers = append(tracked.subscribers, events)
+	unsubscribe := func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		tracked.unsubscribe(events)
+	}
+	return events, unsubscribe, nil
+}
+
+// notify sends an event to the subscribers of the job without blocking, dropping it for subscribers lagging behind.
+func (t *trackedJob) notify(event model.JobEvent) {
+	for _, subscriber := range t.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// unsubscribe removes a subscriber from the job and closes its channel, unless the job already did.
+func (t *trackedJob) unsubscribe(events chan model.JobEvent) {
+	for i, subscriber := range t.subscribers {
+		if subscriber == events {
+			t.subscribers = append(t.subscribers[:i], t.subscribers[i+1:]...)
+			close(events)
+			return
+		}
+	}
+}
+
+// closeSubscribers sends the final event to the subscribers of the job and closes their channels.
+// The final event is never dropped: for a subscriber lagging behind, the oldest queued event makes room for it.
+func (t *trackedJob) closeSubscribers() {
+	event := model.JobEvent{Type: model.EventFinished, Job: t.job}
+	for _, subscriber := range t.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			// The events are only sent under the lock of the registry, the room made here cannot be taken
+			select {
+			case <-subscriber:
+			default:
+			}
+			subscriber <- event
+		}
+		close(subscriber)
+	}
+	t.subscribers = nil
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+// ErrQualityCheckFailed is returned when documents violate a data-quality rule whose severity fails the run.
+var ErrQualityCheckFailed = errors.New("documents violate a data-quality rule failing the run")
+
+// qualityRule is a data-quality rule checked on the documents of a docs type before they are indexed.
+type qualityRule struct {
+	name     string // Name of the rule, prefixed with its docs type
+	severity string // Severity of the rule, see model.SeverityDrop, model.SeverityWarn and model.SeverityFail
+	message  string // Description of the violation
+	// check returns the identifiers of the documents violating the rule among the items
+	check func(ctx context.Context, items []interface{}) ([]string, error)
+}
+
+// qualityRules returns the data-quality rules of the docs type, with the severities overridden by the configuration.
+func (api indexerApi) qualityRules(docsType string) []qualityRule {
+	var rules []qualityRule
+	switch docsType {
+	case Cats:
+		rules = []qualityRule{nameRequired(Cats, model.SeverityWarn, func(category *model.Category) string { return category.Name })}
+	case Tags:
+		rules = []qualityRule{nameRequired(Tags, model.SeverityWarn, func(tag *model.Tag) string { return tag.Name })}
+	case Walls:
+		rules = []qualityRule{nameRequired(Walls, model.SeverityWarn, func(wall *model.Wall) string { return wall.Name })}
+	case Blocks:
+		rules = []qualityRule{nameRequired(Blocks, model.SeverityWarn, func(block *model.Block) string { return block.Name })}
+	case Programs:
+		rules = []qualityRule{nameRequired(Programs, model.SeverityDrop, func(program *model.Program) string { return program.Name })}
+	case Episodes:
+		rules = []qualityRule{
+			nameRequired(Episodes, model.SeverityDrop, func(episode *model.Episode) string { return episode.Name }),
+			{
+				name:     Episodes + ".program-exists",
+				severity: model.SeverityDrop,
+				message:  "the program of the episode does not exist",
+				check:    api.missingPrograms,
+			},
+			// Only reported by the full indexations, see duplicatePositions
+			{
+				name:     Episodes + ".position-unique",
+				severity: model.SeverityWarn,
+				message:  "the position of the episode is shared with another episode of its program",
+				check:    duplicatePositions,
+			},
+		}
+	case Medias:
+		rules = []qualityRule{{
+			name:     Medias + ".direct-link-required",
+			severity: model.SeverityDrop,
+			message:  "the direct link of the media is blank",
+			check: itemCheck(func(media *model.Media) bool {
+				return strings.TrimSpace(media.DirectLink) == ""
+			}),
+		}}
+	}
+
+	for i, rule := range rules {
+		switch severity := api.severities[rule.name]; severity {
+		case model.SeverityDrop, model.SeverityWarn, model.SeverityFail:
+			rules[i].severity = severity
+		}
+	}
+	return rules
+}
+
+// checkQuality checks the documents of the docs type against its data-quality rules and returns the documents to index
+// along with the violations found. Documents violating a drop rule are left out, violations of a warn rule are logged,
+// and violations of a fail rule fail the check with ErrQualityCheckFailed, still returning the documents and violations.
+// The violations are reported to the job running with the context.
+func (api indexerApi) checkQuality(ctx context.Context, docsType string, items []interface{}) ([]interface{}, []model.QualityViolation, error) {
+	violations := []model.QualityViolation{}
+	dropped := make(map[string]struct{})
+	failed := false
+	for _, rule := range api.qualityRules(docsType) {
+		ids, err := rule.check(ctx, items)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not check rule %s: %w", rule.name, err)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		violations = append(violations, model.QualityViolation{Rule: rule.name, Severity: rule.severity, Message: rule.message, EntityIDs: ids})
+
+		logger := log.Ctx(ctx).With().Str("rule", rule.name).Int("violations", len(ids)).Logger()
+		switch rule.severity {
+		case model.SeverityDrop:
+			logger.Warn().Msg("documents violating a data-quality rule dropped")
+			for _, id := range ids {
+				dropped[id] = struct{}{}
+			}
+		case model.SeverityFail:
+			logger.Error().Msg("documents violate a data-quality rule")
+			failed = true
+		default:
+			logger.Warn().Msg("documents violate a data-quality rule")
+		}
+	}
+
+	kept := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if _, ok := dropped[documentID(item)]; !ok {
+			kept = append(kept, item)
+		}
+	}
+	reportQuality(ctx, violations, len(items)-len(kept))
+	if failed {
+		return kept, violations, ErrQualityCheckFailed
+	}
+	return kept, violations, nil
+}
+
+// missingPrograms returns the identifiers of the episodes whose program does not exist.
+// Only the programs of the episodes are looked up, so that a single episode is checked with a single query.
+func (api indexerApi) missingPrograms(ctx context.Context, items []interface{}) ([]string, error) {
+	referenced := make(map[string]struct{})
+	for _, item := range items {
+		if episode, ok := item.(*model.Episode); ok {
+			referenced[episode.ProgramID] = struct{}{}
+		}
+	}
+	if len(referenced) == 0 {
+		return nil, nil
+	}
+	programIDs := make([]string, 0, len(referenced))
+	for id := range referenced {
+		programIDs = append(programIDs, id)
+	}
+	existing, err := api.programAdapter.FindExistingIDs(ctx, programIDs)
+	if err != nil {
+		return nil, err
+	}
+	programs := make(map[string]struct{}, len(existing))
+	for _, id := range existing {
+		programs[id] = struct{}{}
+	}
+	return itemCheck(func(episode *model.Episode) bool {
+		_, ok := programs[episode.ProgramID]
+		return !ok
+	})(ctx, items)
+}
+
+// duplicatePositions returns the identifiers of the episodes sharing their position with another episode of their program.
+// The positions are only compared among the checked episodes, not with the indexed ones: the rule is meant for the full
+// indexations, which check every episode at once, and cannot report a duplicate brought by a delta or a single document.
+func duplicatePositions(ctx context.Context, items []interface{}) ([]string, error) {
+	type slot struct {
+		programID string
+		position  int
+	}
+	episodes := make(map[slot]int)
+	for _, item := range items {
+		if episode, ok := item.(*model.Episode); ok {
+			episodes[slot{episode.ProgramID, episode.Position}]++
+		}
+	}
+	return itemCheck(func(episode *model.Episode) bool {
+		return episodes[slot{episode.ProgramID, episode.Position}] > 1
+	})(ctx, items)
+}
+
+// nameRequired returns the rule reporting the documents of the docs type whose name is blank.
+func nameRequired[T any](docsType, severity string, name func(item *T) string) qualityRule {
+	return qualityRule{
+		name:     docsType + ".name-required",
+		severity: severity,
+		message:  "the name is blank",
+		check: itemCheck(func(item *T) bool {
+			return strings.TrimSpace(name(item)) == ""
+		}),
+	}
+}
+
+// itemCheck returns a rule check reporting the items of type T that violate the rule one by one.
+func itemCheck[T any](violates func(item *T) bool) func(ctx context.Context, items []interface{}) ([]string, error) {
+	return func(_ context.Context, items []interface{}) ([]string, error) {
+		var ids []string
+		for _, item := range items {
+			if typed, ok := item.(*T); ok && violates(typed) {
+				ids = append(ids, documentID(item))
+			}
+		}
+		return ids, nil
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_CheckQuality_WhenEpisodesViolateRules(t *testing.T) {
+	indexerApi := indexerApi{programAdapter: fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}}}
+	items := []interface{}{
+		&model.Episode{ID: "e1", Name: "Episode", ProgramID: "p1", Position: 1},
+		&model.Episode{ID: "e2", Name: " ", ProgramID: "p1", Position: 2},
+		&model.Episode{ID: "e3", Name: "Episode", ProgramID: "p9", Position: 1},
+		&model.Episode{ID: "e4", Name: "Episode", ProgramID: "p1", Position: 1},
+	}
+
+	kept, violations, err := indexerApi.checkQuality(context.Background(), Episodes, items)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{items[0], items[3]}, kept, "Documents violating a drop rule should be left out")
+	assert.Equal(t, []model.QualityViolation{
+		{Rule: "episodes.name-required", Severity: model.SeverityDrop, Message: "the name is blank", EntityIDs: []string{"e2"}},
+		{Rule: "episodes.program-exists", Severity: model.SeverityDrop, Message: "the program of the episode does not exist", EntityIDs: []string{"e3"}},
+		{Rule: "episodes.position-unique", Severity: model.SeverityWarn, Message: "the position of the episode is shared with another episode of its program", EntityIDs: []string{"e1", "e4"}},
+	}, violations)
+}
+
+func Test_Start_WhenQualityRuleFailsTheRun(t *testing.T) {
+	indexer := newFakeIndexer()
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil, fakeProgramAdapter{programs: []*model.Program{{ID: "p1"}}}, nil, nil,
+		WithQualitySeverities(map[string]string{"programs.name-required": model.SeverityFail}))
+	ctx := context.Background()
+
+	job, err := indexerApi.Start(ctx, JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	finished, err := indexerApi.Wait(ctx, job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobFailed, finished.Status)
+	assert.Contains(t, finished.Error, ErrQualityCheckFailed.Error())
+	assert.Equal(t, []model.QualityViolation{
+		{Rule: "programs.name-required", Severity: model.SeverityFail, Message: "the name is blank", EntityIDs: []string{"p1"}},
+	}, finished.Quality)
+	assert.Empty(t, indexer.documents(finished.IndexName), "No document should be indexed")
+}
+
+func Test_Start_WhenDocumentsAreDroppedByEnrichmentAndQuality(t *testing.T) {
+	indexer := newFakeIndexer()
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil,
+		fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "First"}, {ID: "p2", Name: " "}, {ID: "p3", Name: "Third"}}}, nil, nil,
+		WithEnrichers(Programs, model.SeverityDrop, failingEnricher{ids: []string{"p1"}}))
+	ctx := context.Background()
+
+	job, err := indexerApi.Start(ctx, JobRequest{DocsType: Programs, Mode: ModeFull})
+	assert.NoError(t, err)
+	finished, err := indexerApi.Wait(ctx, job.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, finished.Status)
+	assert.Equal(t, 2, finished.Progress.Dropped, "Documents dropped by the enrichment should be counted with those dropped by the rules")
+	assert.Equal(t, []model.QualityViolation{
+		{Rule: "programs.name-required", Severity: model.SeverityDrop, Message: "the name is blank", EntityIDs: []string{"p2"}},
+	}, finished.Quality)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// Reconcile removes from the index currently holding the latest alias the documents of the entities of the docs type
+// that no longer exist, such as the hard-deleted ones, by comparing the identifiers of the documents with those of the entities.
+func (api indexerApi) Reconcile(ctx context.Context, docsType string) error {
+	if !SupportsMode(docsType, ModeReconcile) {
+		return ErrUnsupportedMode
+	}
+	latestIndexName := api.aliasedIndex(ctx, latestAlias, docsType)
+	if latestIndexName == "" {
+		return ErrNoLatestIndex
+	}
+	reportIndexName(ctx, latestIndexName)
+
+	// The documents are listed before the entities, so that a document indexed in between is never taken for a stale one
+	reportPhase(ctx, model.PhaseLoad)
+	documentIDs, err := api.indexer.DocumentIDs(ctx, latestIndexName)
+	if err != nil {
+		return fmt.Errorf("could not list the documents of %s: %w", latestIndexName, err)
+	}
+	entityIDs, err := api.loadIDs(ctx, docsType)
+	if err != nil {
+		return fmt.Errorf("could not find %s identifiers: %w", docsType, err)
+	}
+	reportProgress(ctx, func(progress *model.Progress) {
+		progress.RowsRead = len(entityIDs)
+		progress.Documents = len(documentIDs)
+	})
+
+	existing := make(map[string]bool, len(entityIDs))
+	for _, id := range entityIDs {
+		existing[id] = true
+	}
+	var staleIDs []string
+	for _, id := range documentIDs {
+		if !existing[id] {
+			staleIDs = append(staleIDs, id)
+		}
+	}
+	return api.remove(ctx, latestIndexName, staleIDs)
+}
+
+// remove deletes the documents with the given identifiers from the index in bulk batches, reporting the removals.
+func (api indexerApi) remove(ctx context.Context, indexName string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	reportPhase(ctx, model.PhaseRemove)
+	for start := 0; start < len(ids); start += bulkBatchSize {
+		end := min(start+bulkBatchSize, len(ids))
+		removed, err := api.indexer.DeleteDocuments(ctx, indexName, ids[start:end])
+		reportProgress(ctx, func(progress *model.Progress) {
+			progress.Removed += removed
+		})
+		if err != nil {
+			return fmt.Errorf("could not delete documents from %s: %w", indexName, err)
+		}
+	}
+	return nil
+}
+
+// loadIDs retrieves the identifiers of all the entities of the given docs type.
+func (api indexerApi) loadIDs(ctx context.Context, docsType string) ([]string, error) {
+	switch docsType {
+	case Programs:
+		return api.programAdapter.FindIDs(ctx)
+	case Episodes:
+		return api.episodeAdapter.FindIDs(ctx)
+	default:
+		return nil, ErrUnsupportedMode
+	}
+}
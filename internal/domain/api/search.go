@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"sort"
+	"strings"
+)
+
+// SuggestDocsTypes are the docs types whose names are suggested, in the order of their groups.
+var SuggestDocsTypes = []string{Programs, Episodes, Tags, Cats}
+
+// FederatedDocsTypes are the docs types searched together, in the order of their groups.
+var FederatedDocsTypes = []string{Programs, Episodes, Cats, Tags, Walls}
+
+const (
+	DefaultSuggestSize = 5  // Number of suggestions per docs type when none is requested
+	MaxSuggestSize     = 20 // Maximum number of suggestions per docs type
+
+	DefaultSearchLimit = 20    // Number of documents of a search page when none is requested
+	MaxSearchLimit     = 100   // Maximum number of documents of a search page
+	MaxSearchWindow    = 10000 // Maximum number of documents up to the end of a search page
+
+	DefaultFederatedSize = 5  // Number of documents per docs type of a federated search when none is configured nor requested
+	DefaultTopHits       = 10 // Number of blended top hits of a federated search when none is configured
+)
+
+// searchSorts are the sorts of the search results of each searchable docs type, the first one being the default.
+var searchSorts = map[string][]string{
+	Programs: {model.SortRelevance, model.SortName, model.SortEpisodeCount},
+	Episodes: {model.SortRelevance, model.SortName, model.SortPosition},
+	Medias:   {model.SortRelevance},
+}
+
+var (
+	// ErrEmptyQuery is returned when searching without any word to search.
+	ErrEmptyQuery = errors.New("empty search query")
+	// ErrInvalidSearch is returned when a search has a filter, sort or page its docs type does not support.
+	ErrInvalidSearch = errors.New("invalid search")
+)
+
+// Search interface defines the queries of the latest indexes.
+type Search interface {
+	Suggest(ctx context.Context, query string, docsTypes []string, size int) (model.Suggestions, error)
+	Search(ctx context.Context, docsType string, query model.SearchQuery) (model.SearchResult, error)
+	Federate(ctx context.Context, text string, docsTypes []string, size int) (model.FederatedResult, error)
+}
+
+// searchApi struct implements the Search interface
+type searchApi struct {
+	searcher      port.Searcher
+	federatedSize int
+	topHits       int
+	boosts        map[string]float64
+}
+
+// SearchOption configures a search api.
+type SearchOption func(api *searchApi)
+
+// NewSearchApi returns a new instance of searchApi
+func NewSearchApi(searcher port.Searcher, options ...SearchOption) Search {
+	api := &searchApi{
+		searcher:      searcher,
+		federatedSize: DefaultFederatedSize,
+		topHits:       DefaultTopHits,
+		boosts:        map[string]float64{},
+	}
+	for _, option := range options {
+		option(api)
+	}
+	return api
+}
+
+// WithFederatedSearch sets the number of documents per docs type of a federated search when none is requested,
+// the number of its blended top hits, and the multipliers of the scores of each docs type ranking the top hits.
+// Non-positive numbers keep the defaults, the scores of the docs types without boost are kept as is.
+func WithFederatedSearch(size, topHits int, boosts map[string]float64) SearchOption {
+	return func(api *searchApi) {
+		if size > 0 {
+			api.federatedSize = size
+		}
+		if topHits > 0 {
+			api.topHits = topHits
+		}
+		for docsType, boost := range boosts {
+			api.boosts[docsType] = boost
+		}
+	}
+}
+
+// Suggest returns the documents whose name completes the query, grouped by docs type in the order of SuggestDocsTypes.
+// The docs types default to SuggestDocsTypes, the number of suggestions per docs type defaults to DefaultSuggestSize
+// and is capped to MaxSuggestSize.
+func (api searchApi) Suggest(ctx context.Context, query string, docsTypes []string, size int) (model.Suggestions, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return model.Suggestions{}, ErrEmptyQuery
+	}
+	requested := make(map[string]bool, len(docsTypes))
+	for _, docsType := range docsTypes {
+		if !isSuggestDocsType(docsType) {
+			return model.Suggestions{}, fmt.Errorf("%w: %s has no suggestions", ErrUnknownDocsType, docsType)
+		}
+		requested[docsType] = true
+	}
+	var selected []string
+	for _, docsType := range SuggestDocsTypes {
+		if len(requested) == 0 || requested[docsType] {
+			selected = append(selected, docsType)
+		}
+	}
+	if size <= 0 {
+		size = DefaultSuggestSize
+	}
+	size = min(size, MaxSuggestSize)
+
+	found, err := api.searcher.Suggest(ctx, query, selected, size)
+	if err != nil {
+		return model.Suggestions{}, fmt.Errorf("could not suggest %q: %w", query, err)
+	}
+	suggestions := model.Suggestions{Query: query, Groups: make([]model.SuggestionGroup, 0, len(selected))}
+	for _, docsType := range selected {
+		group := model.SuggestionGroup{DocsType: docsType, Suggestions: found[docsType]}
+		if group.Suggestions == nil {
+			group.Suggestions = []model.Suggestion{}
+		}
+		suggestions.Groups = append(suggestions.Groups, group)
+	}
+	return suggestions, nil
+}
+
+// Search returns the page of the documents of the docs type matching the query, in its latest index.
+// The program filter applies to the episodes, the kind filter to the episodes and medias. The sort defaults to
+// the relevance, in descending order, the other sorts to the ascending order. The limit defaults to DefaultSearchLimit
+// and is capped to MaxSearchLimit, the page cannot end beyond MaxSearchWindow.
+func (api searchApi) Search(ctx context.Context, docsType string, query model.SearchQuery) (model.SearchResult, error) {
+	sorts, ok := searchSorts[docsType]
+	if !ok {
+		return model.SearchResult{}, fmt.Errorf("%w: %s is not searchable", ErrUnknownDocsType, docsType)
+	}
+	query.Text = strings.TrimSpace(query.Text)
+	if query.ProgramID != "" && docsType != Episodes {
+		return model.SearchResult{}, fmt.Errorf("%w: %s cannot be filtered by program", ErrInvalidSearch, docsType)
+	}
+	if query.Kind != "" && docsType != Episodes && docsType != Medias {
+		return model.SearchResult{}, fmt.Errorf("%w: %s cannot be filtered by media kind", ErrInvalidSearch, docsType)
+	}
+
+	if query.Sort == "" {
+		query.Sort = sorts[0]
+	}
+	if !contains(sorts, query.Sort) {
+		return model.SearchResult{}, fmt.Errorf("%w: %s cannot be sorted by %s", ErrInvalidSearch, docsType, query.Sort)
+	}
+	switch query.Order {
+	case "":
+		query.Order = model.OrderAsc
+		if query.Sort == model.SortRelevance {
+			query.Order = model.OrderDesc
+		}
+	case model.OrderAsc, model.OrderDesc:
+	default:
+		return model.SearchResult{}, fmt.Errorf("%w: unknown order %s", ErrInvalidSearch, query.Order)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchLimit
+	}
+	query.Limit = min(query.Limit, MaxSearchLimit)
+	query.Offset = max(query.Offset, 0)
+	if query.Offset+query.Limit > MaxSearchWindow {
+		return model.SearchResult{}, fmt.Errorf("%w: the page cannot end beyond %d documents", ErrInvalidSearch, MaxSearchWindow)
+	}
+
+	result, err := api.searcher.Search(ctx, docsType, query)
+	if err != nil {
+		return model.SearchResult{}, fmt.Errorf("could not search %s: %w", docsType, err)
+	}
+	if result.Hits == nil {
+		result.Hits = []model.SearchHit{}
+	}
+	result.DocsType, result.Limit, result.Offset = docsType, query.Limit, query.Offset
+	return result, nil
+}
+
+// Federate searches the text in the documents of several docs types at once, FederatedDocsTypes by default.
+// It returns the best documents of each docs type, the number of documents requested per docs type defaulting
+// to the configured one and being capped to MaxSearchLimit, along with the best documents of all docs types
+// blended by score, each score being multiplied by the boost of its docs type.
+func (api searchApi) Federate(ctx context.Context, text string, docsTypes []string, size int) (model.FederatedResult, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return model.FederatedResult{}, ErrEmptyQuery
+	}
+	requested := make(map[string]bool, len(docsTypes))
+	for _, docsType := range docsTypes {
+		if !contains(FederatedDocsTypes, docsType) {
+			return model.FederatedResult{}, fmt.Errorf("%w: %s is not searchable", ErrUnknownDocsType, docsType)
+		}
+		requested[docsType] = true
+	}
+	var selected []string
+	for _, docsType := range FederatedDocsTypes {
+		if len(requested) == 0 || requested[docsType] {
+			selected = append(selected, docsType)
+		}
+	}
+	if size <= 0 {
+		size = api.federatedSize
+	}
+	size = min(size, MaxSearchLimit)
+
+	// Enough documents of each docs type are fetched for the top hits to be the best of all docs types
+	found, err := api.searcher.SearchAll(ctx, text, selected, max(size, api.topHits))
+	if err != nil {
+		return model.FederatedResult{}, fmt.Errorf("could not search %q: %w", text, err)
+	}
+
+	result := model.FederatedResult{Query: text, Groups: make([]model.SearchResult, 0, len(selected)), TopHits: []model.FederatedHit{}}
+	for _, docsType := range selected {
+		group := found[docsType]
+		for _, hit := range group.Hits {
+			boost, ok := api.boosts[docsType]
+			if !ok {
+				boost = 1
+			}
+			result.TopHits = append(result.TopHits, model.FederatedHit{DocsType: docsType, SearchHit: hit, BoostedScore: hit.Score * boost})
+		}
+		group.DocsType, group.Limit, group.Offset = docsType, size, 0
+		group.Hits = append([]model.SearchHit{}, group.Hits[:min(len(group.Hits), size)]...)
+		result.Groups = append(result.Groups, group)
+	}
+	sort.SliceStable(result.TopHits, func(i, j int) bool {
+		return result.TopHits[i].BoostedScore > result.TopHits[j].BoostedScore
+	})
+	result.TopHits = result.TopHits[:min(len(result.TopHits), api.topHits)]
+	return result, nil
+}
+
+// isSuggestDocsType reports whether the names of the docs type are suggested.
+func isSuggestDocsType(docsType string) bool {
+	return contains(SuggestDocsTypes, docsType)
+}
+
+// contains reports whether the value is one of the values.
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
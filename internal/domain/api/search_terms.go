@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoSearchTerms is returned when managing the search terms of an indexer api configured without a store for them.
+	ErrNoSearchTerms = errors.New("search terms store is not configured")
+	// ErrInvalidSearchTerms is returned when a synonym rule is malformed.
+	ErrInvalidSearchTerms = errors.New("invalid search terms")
+)
+
+// SearchTerms returns the synonyms and stopwords applied by the analyzers.
+func (api indexerApi) SearchTerms(ctx context.Context) (model.SearchTerms, error) {
+	if api.searchTerms == nil {
+		return model.SearchTerms{}, ErrNoSearchTerms
+	}
+	return api.searchTerms.Find(ctx)
+}
+
+// UpdateSearchTerms replaces the synonyms and stopwords, applied by the analyzers of the indexes created from now on.
+// When reload is set, the search analyzers of the latest, previous and in-progress indexes of every docs type are
+// reloaded as well, without reindexing nor closing them: the synonyms apply to the searches at once, the stopwords
+// only from the next rebuild. An index being built, or rolled back to, thus searches with the same synonyms.
+func (api indexerApi) UpdateSearchTerms(ctx context.Context, terms model.SearchTerms, reload bool) (model.SearchTermsUpdate, error) {
+	if api.searchTerms == nil {
+		return model.SearchTermsUpdate{}, ErrNoSearchTerms
+	}
+	terms, err := normalizeSearchTerms(terms)
+	if err != nil {
+		return model.SearchTermsUpdate{}, err
+	}
+	terms.UpdatedAt = time.Now().UTC()
+	if err := api.searchTerms.Save(ctx, terms); err != nil {
+		return model.SearchTermsUpdate{}, fmt.Errorf("could not save the search terms: %w", err)
+	}
+
+	update := model.SearchTermsUpdate{SearchTerms: terms, Reloaded: []string{}}
+	if !reload {
+		return update, nil
+	}
+	for _, docsType := range DocsTypes {
+		for _, alias := range []string{latestAlias, previousAlias, inProgressAlias} {
+			indexName := api.aliasedIndex(ctx, alias, docsType)
+			if indexName == "" {
+				continue
+			}
+			if err := api.indexer.ReloadSearchTerms(ctx, indexName, docsType, terms); err != nil {
+				return update, fmt.Errorf("could not reload the search terms of %s: %w", indexName, err)
+			}
+			update.Reloaded = append(update.Reloaded, indexName)
+		}
+	}
+	log.Ctx(ctx).Info().Strs("indexes", update.Reloaded).Msg("search terms reloaded")
+	return update, nil
+}
+
+// indexSearchTerms returns the search terms applied by the analyzers of a new index, none without a store for them.
+func (api indexerApi) indexSearchTerms(ctx context.Context) (model.SearchTerms, error) {
+	if api.searchTerms == nil {
+		return model.SearchTerms{}, nil
+	}
+	return api.searchTerms.Find(ctx)
+}
+
+// normalizeSearchTerms trims the synonym rules and the stopwords, leaving out the blank and duplicate ones,
+// and lower-cases the stopwords. It returns ErrInvalidSearchTerms for a rule that does not map any term:
+// a rule either lists equivalent terms, e.g. "pod, podcast", or maps terms to others, e.g. "actu => actualités".
+func normalizeSearchTerms(terms model.SearchTerms) (model.SearchTerms, error) {
+	normalized := model.SearchTerms{Synonyms: []string{}, Stopwords: []string{}}
+	seen := make(map[string]struct{})
+	for _, rule := range terms.Synonyms {
+		rule = strings.TrimSpace(rule)
+		if _, ok := seen[rule]; ok || rule == "" {
+			continue
+		}
+		if !validSynonymRule(rule) {
+			return model.SearchTerms{}, fmt.Errorf("%w: synonym rule %q maps no term", ErrInvalidSearchTerms, rule)
+		}
+		seen[rule] = struct{}{}
+		normalized.Synonyms = append(normalized.Synonyms, rule)
+	}
+	seen = make(map[string]struct{})
+	for _, stopword := range terms.Stopwords {
+		stopword = strings.ToLower(strings.TrimSpace(stopword))
+		if _, ok := seen[stopword]; ok || stopword == "" {
+			continue
+		}
+		seen[stopword] = struct{}{}
+		normalized.Stopwords = append(normalized.Stopwords, stopword)
+	}
+	return normalized, nil
+}
+
+// validSynonymRule reports whether the rule lists at least two terms, or maps at least one term to at least one other.
+func validSynonymRule(rule string) bool {
+	sides := strings.Split(rule, "=>")
+	switch len(sides) {
+	case 1:
+		return len(synonymTerms(sides[0])) >= 2
+	case 2:
+		return len(synonymTerms(sides[0])) >= 1 && len(synonymTerms(sides[1])) >= 1
+	default:
+		return false
+	}
+}
+
+// synonymTerms returns the non-blank terms of a comma-separated list.
+func synonymTerms(list string) []string {
+	var terms []string
+	for _, term := range strings.Split(list, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
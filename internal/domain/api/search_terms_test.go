@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/infrastructure/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_UpdateSearchTerms_WhenAppliedToLiveIndexes(t *testing.T) {
+	ctx := context.Background()
+	indexer := newIndexerWithLatest(t)
+	_ = indexer.CreateIndex(ctx, "programs-0", Programs, model.SearchTerms{})
+	_ = indexer.CreateAlias(ctx, "programs-0", previousAlias)
+	_ = indexer.CreateIndex(ctx, "programs-2", Programs, model.SearchTerms{})
+	_ = indexer.CreateAlias(ctx, "programs-2", inProgressAlias)
+	_ = indexer.CreateIndex(ctx, "programs-old", Programs, model.SearchTerms{})
+	indexerApi := NewIndexerApi(indexer, nil, nil, nil, nil, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}}}, nil, nil,
+		WithSearchTerms(memory.NewSearchTermsAdapter()))
+
+	update, err := indexerApi.UpdateSearchTerms(ctx, model.SearchTerms{
+		Synonyms:  []string{" pod, podcast ", "actu => actualités", ""},
+		Stopwords: []string{"Le", "le"},
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pod, podcast", "actu => actualités"}, update.Synonyms)
+	assert.Equal(t, []string{"le"}, update.Stopwords)
+	assert.Equal(t, []string{"programs-1", "programs-0", "programs-2"}, update.Reloaded, "The aliased indexes should be reloaded")
+	assert.Equal(t, update.SearchTerms, indexer.terms["programs-1"])
+	assert.Equal(t, update.SearchTerms, indexer.terms["programs-2"])
+	assert.Equal(t, model.SearchTerms{}, indexer.terms["programs-old"], "Indexes without alias should not be reloaded")
+
+	terms, err := indexerApi.SearchTerms(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, update.SearchTerms, terms)
+
+	// New indexes are created with the saved search terms
+	assert.NoError(t, indexerApi.Reindex(ctx, Programs))
+	latest := indexer.IndexByAlias(ctx, latestAlias)
+	assert.Len(t, latest, 1)
+	assert.Equal(t, update.SearchTerms, indexer.terms[latest[0]])
+}
+
+func Test_UpdateSearchTerms_WhenRuleIsInvalid(t *testing.T) {
+	indexerApi := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, nil, nil, nil, WithSearchTerms(memory.NewSearchTermsAdapter()))
+
+	for _, rule := range []string{"podcast", "actu =>", "a => b => c"} {
+		_, err := indexerApi.UpdateSearchTerms(context.Background(), model.SearchTerms{Synonyms: []string{rule}}, false)
+		assert.ErrorIs(t, err, ErrInvalidSearchTerms, rule)
+	}
+
+	_, err := NewIndexerApi(newFakeIndexer(), nil, nil, nil, nil, nil, nil, nil).SearchTerms(context.Background())
+	assert.ErrorIs(t, err, ErrNoSearchTerms)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// fakeSearcher is a port.Searcher returning the same suggestions and results whatever the query, recording its last request.
+type fakeSearcher struct {
+	suggestions map[string][]model.Suggestion
+	result      model.SearchResult
+	results     map[string]model.SearchResult
+	docsTypes   []string
+	size        int
+	query       model.SearchQuery
+}
+
+func (f *fakeSearcher) Suggest(ctx context.Context, query string, docsTypes []string, size int) (map[string][]model.Suggestion, error) {
+	f.docsTypes, f.size = docsTypes, size
+	return f.suggestions, nil
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, docsType string, query model.SearchQuery) (model.SearchResult, error) {
+	f.query = query
+	return f.result, nil
+}
+
+func (f *fakeSearcher) SearchAll(ctx context.Context, text string, docsTypes []string, size int) (map[string]model.SearchResult, error) {
+	f.docsTypes, f.size = docsTypes, size
+	return f.results, nil
+}
+
+func Test_Suggest_WhenDocsTypesAreRequested(t *testing.T) {
+	searcher := &fakeSearcher{suggestions: map[string][]model.Suggestion{Programs: {{ID: "p1", Text: "Podcast", Score: 2}}}}
+	searchApi := NewSearchApi(searcher)
+
+	suggestions, err := searchApi.Suggest(context.Background(), " pod ", []string{Cats, Programs}, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{Programs, Cats}, searcher.docsTypes, "Docs types should follow the order of the groups")
+	assert.Equal(t, MaxSuggestSize, searcher.size)
+	assert.Equal(t, model.Suggestions{Query: "pod", Groups: []model.SuggestionGroup{
+		{DocsType: Programs, Suggestions: []model.Suggestion{{ID: "p1", Text: "Podcast", Score: 2}}},
+		{DocsType: Cats, Suggestions: []model.Suggestion{}},
+	}}, suggestions)
+
+	_, err = searchApi.Suggest(context.Background(), "pod", nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, SuggestDocsTypes, searcher.docsTypes)
+	assert.Equal(t, DefaultSuggestSize, searcher.size)
+}
+
+func Test_Suggest_WhenRequestIsInvalid(t *testing.T) {
+	searchApi := NewSearchApi(&fakeSearcher{})
+
+	_, err := searchApi.Suggest(context.Background(), " ", nil, 0)
+	assert.ErrorIs(t, err, ErrEmptyQuery)
+	_, err = searchApi.Suggest(context.Background(), "pod", []string{Walls}, 0)
+	assert.ErrorIs(t, err, ErrUnknownDocsType)
+}
+
+func Test_Search_WhenQueryIsDefaulted(t *testing.T) {
+	searcher := &fakeSearcher{result: model.SearchResult{Total: 1, Hits: []model.SearchHit{{ID: "e1"}}}}
+	searchApi := NewSearchApi(searcher)
+
+	result, err := searchApi.Search(context.Background(), Episodes, model.SearchQuery{Text: " pod ", ProgramID: "p1", Offset: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, model.SearchQuery{Text: "pod", ProgramID: "p1", Sort: model.SortRelevance, Order: model.OrderDesc, Limit: DefaultSearchLimit}, searcher.query)
+	assert.Equal(t, model.SearchResult{DocsType: Episodes, Total: 1, Hits: []model.SearchHit{{ID: "e1"}}, Limit: DefaultSearchLimit}, result)
+
+	_, err = searchApi.Search(context.Background(), Programs, model.SearchQuery{Sort: model.SortName, Limit: 500})
+	assert.NoError(t, err)
+	assert.Equal(t, model.OrderAsc, searcher.query.Order)
+	assert.Equal(t, MaxSearchLimit, searcher.query.Limit)
+}
+
+func Test_Search_WhenQueryIsInvalid(t *testing.T) {
+	searchApi := NewSearchApi(&fakeSearcher{})
+
+	for name, query := range map[string]model.SearchQuery{
+		"program filter": {ProgramID: "p1"},
+		"kind filter":    {Kind: "audio"},
+		"sort":           {Sort: model.SortPosition},
+		"order":          {Order: "up"},
+		"window":         {Offset: MaxSearchWindow},
+	} {
+		_, err := searchApi.Search(context.Background(), Programs, query)
+		assert.ErrorIs(t, err, ErrInvalidSearch, name)
+	}
+	_, err := searchApi.Search(context.Background(), Tags, model.SearchQuery{})
+	assert.ErrorIs(t, err, ErrUnknownDocsType)
+}
+
+func Test_Federate_WhenScoresAreBoosted(t *testing.T) {
+	searcher := &fakeSearcher{results: map[string]model.SearchResult{
+		Programs: {Total: 12, Hits: []model.SearchHit{{ID: "p1", Score: 3}, {ID: "p2", Score: 1}}},
+		Tags:     {Total: 1, Hits: []model.SearchHit{{ID: "t1", Score: 4}}},
+	}}
+	searchApi := NewSearchApi(searcher, WithFederatedSearch(1, 2, map[string]float64{Programs: 2}))
+
+	result, err := searchApi.Federate(context.Background(), " pod ", []string{Tags, Programs}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{Programs, Tags}, searcher.docsTypes)
+	assert.Equal(t, 2, searcher.size, "Enough documents should be fetched for the top hits")
+	assert.Equal(t, "pod", result.Query)
+	assert.Equal(t, []model.SearchResult{
+		{DocsType: Programs, Total: 12, Hits: []model.SearchHit{{ID: "p1", Score: 3}}, Limit: 1},
+		{DocsType: Tags, Total: 1, Hits: []model.SearchHit{{ID: "t1", Score: 4}}, Limit: 1},
+	}, result.Groups)
+	assert.Equal(t, []model.FederatedHit{
+		{DocsType: Programs, SearchHit: model.SearchHit{ID: "p1", Score: 3}, BoostedScore: 6},
+		{DocsType: Tags, SearchHit: model.SearchHit{ID: "t1", Score: 4}, BoostedScore: 4},
+	}, result.TopHits)
+
+	_, err = searchApi.Federate(context.Background(), "pod", []string{Medias}, 0)
+	assert.ErrorIs(t, err, ErrUnknownDocsType)
+}
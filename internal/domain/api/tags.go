@@ -2,101 +2,9 @@ package api
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"time"
 )
 
 // Tags handles the indexing of tags
 func (api indexerApi) Tags(ctx context.Context) error {
-	// Initialize indexName with current Unix timestamp
-	indexName := fmt.Sprintf("tags-%v", time.Now().Unix())
-
-	// Create a new index
-	if err := api.indexer.CreateIndex(ctx, indexName, Tags); err != nil {
-		return err
-	}
-
-	// Assign the in-progress alias to the new index
-	if err := api.indexer.CreateAlias(ctx, indexName, "in-progress"); err != nil {
-		return err
-	}
-
-	// Retrieve all tags
-	tags, err := api.tagAdapter.FindAll(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Bulk index tags
-	if err := api.indexer.RecordBulkItems(ctx, indexName, mapArrayToInterface(tags), 5, 5); err != nil {
-		return err
-	}
-
-	// Get all indexes associated with the latest alias
-	latestIndexesNames := api.indexer.IndexByAlias(ctx, "latest")
-
-	// Find the latest index name for tags
-	var tagsLatestIndexName string
-	for _, latestIndexName := range latestIndexesNames {
-		if strings.Contains(latestIndexName, "tags") {
-			tagsLatestIndexName = latestIndexName
-		}
-	}
-
-	// If there is no latest tags index
-	if tagsLatestIndexName == "" {
-		// Make the in-progress index the latest index
-		if err := api.indexer.DeleteAlias(ctx, indexName, "in-progress"); err != nil {
-			return err
-		}
-		if err := api.indexer.CreateAlias(ctx, indexName, "latest"); err != nil {
-			return err
-		}
-	} else {
-		// Handle existing latest index
-		previousIndexesNames := api.indexer.IndexByAlias(ctx, "previous")
-
-		// Find the previous index name for tags
-		var tagsPreviousIndexName string
-		for _, iName := range previousIndexesNames {
-			if strings.Contains(iName, "tags") {
-				tagsPreviousIndexName = iName
-			}
-		}
-
-		if tagsPreviousIndexName == "" {
-			// Make the latest index the previous index
-			if err := api.indexer.DeleteAlias(ctx, tagsLatestIndexName, "latest"); err != nil {
-				return err
-			}
-			if err := api.indexer.CreateAlias(ctx, tagsLatestIndexName, "previous"); err != nil {
-				return err
-			}
-		} else {
-			// Delete the previous index
-			if err := api.indexer.DeleteIndexes(ctx, []string{tagsPreviousIndexName}); err != nil {
-				return err
-			}
-			// Update aliases accordingly
-			if err := api.indexer.DeleteAlias(ctx, tagsLatestIndexName, "latest"); err != nil {
-				return err
-			}
-			if err := api.indexer.CreateAlias(ctx, tagsLatestIndexName, "previous"); err != nil {
-				return err
-			}
-		}
-
-		// Always remove the in-progress alias from the new index
-		if err := api.indexer.DeleteAlias(ctx, indexName, "in-progress"); err != nil {
-			return err
-		}
-
-		// Create latest alias for the new index
-		if err := api.indexer.CreateAlias(ctx, indexName, "latest"); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return api.index(ctx, Tags)
 }
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// traceIDKey is the context key of the trace identifier.
+type traceIDKey struct{}
+
+// WithTraceID returns a copy of the context carrying the trace identifier of the request that triggered the work.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID returns the trace identifier carried by the context, or an empty string.
+func TraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
+
+// callerKey is the context key of the identity of the caller.
+type callerKey struct{}
+
+// WithCaller returns a copy of the context carrying the identity of the caller that requested the work.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// Caller returns the identity of the caller carried by the context, or an empty string.
+func Caller(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// jobContext returns the root context of a job for the docs type. It is detached from the caller's context,
+// which may end before the job, keeps its trace identifier and logger, and has the deadline of the docs type.
+func (api indexerApi) jobContext(parent context.Context, docsType string) (context.Context, context.CancelFunc) {
+	logger := zerolog.Ctx(parent)
+	if logger.GetLevel() == zerolog.Disabled {
+		logger = &log.Logger
+	}
+	ctx := logger.WithContext(WithTraceID(context.Background(), TraceID(parent)))
+
+	timeout, ok := api.timeouts[docsType]
+	if !ok {
+		timeout = api.defaultTimeout
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
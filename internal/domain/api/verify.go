@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"sort"
+	"time"
+)
+
+// VerifyOptions selects what a consistency check compares and whether it repairs the differences.
+type VerifyOptions struct {
+	Content bool // Compare the content of the documents with their entities, not only their identifiers
+	Repair  bool // Index the missing and differing documents and remove the extra ones
+}
+
+// Verify compares the entities of the docs type in the database with the documents of its latest index
+// and reports the missing, extra and, if requested, differing documents. The differences are repaired on request:
+// missing and differing documents are indexed, into the index being built too if any, and extra ones are removed.
+// The differing documents are overwritten whatever their version: their entity usually did not change since they
+// were indexed, the enrichment or a manual edit did.
+func (api indexerApi) Verify(ctx context.Context, docsType string, options VerifyOptions) (model.ConsistencyReport, error) {
+	if !isDocsType(docsType) {
+		return model.ConsistencyReport{}, ErrUnknownDocsType
+	}
+	latestIndexName := api.aliasedIndex(ctx, latestAlias, docsType)
+	if latestIndexName == "" {
+		return model.ConsistencyReport{}, ErrNoLatestIndex
+	}
+	report := model.ConsistencyReport{
+		DocsType:  docsType,
+		IndexName: latestIndexName,
+		Content:   options.Content,
+		Missing:   []string{},
+		Extra:     []string{},
+		Differing: []string{},
+		CheckedAt: time.Now(),
+	}
+
+	// The documents are listed before the entities, so that a document indexed in between is never taken for an extra one
+	sources, err := api.documentSources(ctx, latestIndexName, options.Content)
+	if err != nil {
+		return model.ConsistencyReport{}, fmt.Errorf("could not list the documents of %s: %w", latestIndexName, err)
+	}
+	items, err := api.load(ctx, docsType)
+	if err != nil {
+		return model.ConsistencyReport{}, fmt.Errorf("could not find %s: %w", docsType, err)
+	}
+	report.Entities = len(items)
+	// The entities are prepared as they are indexed, so that the documents left out are not reported missing.
+	// A violation failing the run only fails a repair, which would index the documents.
+	items, _, err = api.prepare(ctx, docsType, items)
+	if err != nil && (options.Repair || !errors.Is(err, ErrQualityCheckFailed)) {
+		return model.ConsistencyReport{}, err
+	}
+	report.Documents = len(sources)
+
+	entities := make(map[string]interface{}, len(items))
+	var missing, differing []interface{}
+	for _, item := range items {
+		id := documentID(item)
+		entities[id] = item
+		source, indexed := sources[id]
+		switch {
+		case !indexed:
+			report.Missing = append(report.Missing, id)
+			missing = append(missing, item)
+		case options.Content && !sameContent(item, source):
+			report.Differing = append(report.Differing, id)
+			differing = append(differing, item)
+		}
+	}
+	for id := range sources {
+		if _, ok := entities[id]; !ok {
+			report.Extra = append(report.Extra, id)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Extra)
+	sort.Strings(report.Differing)
+
+	if options.Repair {
+		outcome, err := api.repair(ctx, docsType, missing, differing, report.Extra)
+		report.Repair = &outcome
+		if err != nil {
+			return report, fmt.Errorf("could not repair %s: %w", docsType, err)
+		}
+	}
+	return report, nil
+}
+
+// documentSources returns the documents of the index by identifier, with their source if requested.
+func (api indexerApi) documentSources(ctx context.Context, indexName string, withSource bool) (map[string][]byte, error) {
+	if withSource {
+		return api.indexer.DocumentSources(ctx, indexName)
+	}
+	ids, err := api.indexer.DocumentIDs(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		sources[id] = nil
+	}
+	return sources, nil
+}
+
+// repair indexes the missing items, overwrites the differing ones and removes the documents with the given identifiers
+// from the indexes receiving single-document changes. The documents not written into the latest index are listed.
+func (api indexerApi) repair(ctx context.Context, docsType string, missing, differing []interface{}, extraIDs []string) (model.RepairOutcome, error) {
+	outcome := model.RepairOutcome{NotRepaired: []string{}}
+	indexNames, err := api.documentIndexes(ctx, docsType)
+	if err != nil {
+		return outcome, err
+	}
+	for _, indexName := range indexNames {
+		// A missing document indexed in the meantime is fresher, the external version is checked
+		for _, write := range []struct {
+			items []interface{}
+			bulk  func(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error)
+		}{
+			{missing, api.indexer.RecordBulkItems},
+			{differing, api.indexer.OverwriteBulkItems},
+		} {
+			for start := 0; start < len(write.items); start += bulkBatchSize {
+				end := min(start+bulkBatchSize, len(write.items))
+				result, err := write.bulk(ctx, indexName, write.items[start:end], 5, 5)
+				if indexName == indexNames[0] {
+					outcome.Indexed += result.Indexed
+					outcome.Stale += result.Stale
+					outcome.NotRepaired = append(outcome.NotRepaired, result.NotWritten...)
+				}
+				if err != nil {
+					return outcome, err
+				}
+			}
+		}
+	}
+	for _, indexName := range indexNames {
+		for start := 0; start < len(extraIDs); start += bulkBatchSize {
+			end := min(start+bulkBatchSize, len(extraIDs))
+			removed, err := api.indexer.DeleteDocuments(ctx, indexName, extraIDs[start:end])
+			if indexName == indexNames[0] {
+				outcome.Removed += removed
+			}
+			if err != nil {
+				return outcome, fmt.Errorf("could not delete documents from %s: %w", indexName, err)
+			}
+		}
+	}
+	return outcome, nil
+}
+
+// documentID returns the identifier of the document of an item.
+func documentID(item interface{}) string {
+	if document, ok := item.(model.Document); ok {
+		return document.DocumentID()
+	}
+	return ""
+}
+
+// sameContent reports whether the document built from the item has the same content as the indexed source.
+func sameContent(item interface{}, source []byte) bool {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return false
+	}
+	itemHash, err := contentHash(data)
+	if err != nil {
+		return false
+	}
+	sourceHash, err := contentHash(source)
+	return err == nil && itemHash == sourceHash
+}
+
+// contentHash returns the SHA-256 of the canonical form of a JSON document, whose object keys are sorted,
+// so that documents with the same content have the same hash whatever the order of their fields.
+func contentHash(data []byte) (string, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
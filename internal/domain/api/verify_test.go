@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Verify_WhenIndexDiffersFromDatabase(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1", Name: "Program"}, &model.Program{ID: "p2", Name: "Outdated"}, &model.Program{ID: "p4"})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{
+		{ID: "p1", Name: "Program"},
+		{ID: "p2", Name: "Renamed"},
+		{ID: "p3", Name: "New"},
+	}})
+
+	report, err := indexerApi.Verify(context.Background(), Programs, VerifyOptions{Content: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "programs-1", report.IndexName)
+	assert.Equal(t, []string{"p3"}, report.Missing)
+	assert.Equal(t, []string{"p4"}, report.Extra)
+	assert.Equal(t, []string{"p2"}, report.Differing)
+	assert.Nil(t, report.Repair)
+	assert.Len(t, indexer.documents("programs-1"), 3, "Index should not change without repair")
+}
+
+func Test_Verify_WhenRepairIsRequested(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1", Name: "Program"}, &model.Program{ID: "p4"})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program"}, {ID: "p3", Name: "New"}}})
+	ctx := context.Background()
+
+	report, err := indexerApi.Verify(ctx, Programs, VerifyOptions{Repair: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &model.RepairOutcome{Indexed: 1, Removed: 1, NotRepaired: []string{}}, report.Repair)
+	report, err = indexerApi.Verify(ctx, Programs, VerifyOptions{Content: true})
+	assert.NoError(t, err)
+	assert.True(t, report.Consistent())
+}
+
+func Test_Verify_WhenDifferingDocumentHasSameVersion(t *testing.T) {
+	indexer := newIndexerWithLatest(t, &model.Program{ID: "p1", Name: "Edited by hand", Version: 5})
+	indexerApi := newTestIndexerApi(indexer, fakeProgramAdapter{programs: []*model.Program{{ID: "p1", Name: "Program", Version: 5}}})
+
+	report, err := indexerApi.Verify(context.Background(), Programs, VerifyOptions{Content: true, Repair: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"p1"}, report.Differing)
+	assert.Equal(t, &model.RepairOutcome{Indexed: 1, NotRepaired: []string{}}, report.Repair)
+	assert.Equal(t, []interface{}{&model.Program{ID: "p1", Name: "Program", Version: 5}}, indexer.documents("programs-1"))
+}
@@ -4,9 +4,16 @@ package model
 // Block represents a block entity in the system.
 // A Block is a logical grouping that can contain multiple Programs.
 type Block struct {
-	ID          string    // Unique identifier for the block
-	Name        string    // Name of the block
-	Description string    // Description of the block
-	Kind        string    // Type or category of the block
-	Programs    []Program // List of programs associated with the block
+	ID                   string        // Unique identifier for the block
+	Name                 string        // Name of the block
+	Description          string        // Description of the block
+	Kind                 string        // Type or category of the block
+	Programs             []Program     // List of programs associated with the block
+	Slug                 string        `json:",omitempty"` // URL-friendly form of the name, set by the slug enricher
+	Summary              string        `json:",omitempty"` // Beginning of the plain-text description, set by the normalize enricher
+	RawDescription       string        `json:",omitempty"` // Description as written in the CMS editor, kept by the normalize enricher on request, not searchable
+	Language             string        `json:",omitempty"` // Language detected in the name and description, set by the languages enricher
+	LocalizedName        LocalizedText `json:",omitempty"` // Name by language, set by the languages enricher
+	LocalizedDescription LocalizedText `json:",omitempty"` // Description by language, set by the languages enricher
+	Version              int64         `json:"-"`          // Version of the block, derived from its last update, not indexed
 }
@@ -4,9 +4,16 @@ package model
 // Category represents a category entity in the system.
 // A Category can have a hierarchical relationship with other categories, allowing for nested structures.
 type Category struct {
-	ID          string      // Unique identifier for the category
-	Name        string      // Name of the category
-	Description string      // Description of the category
-	Parent      *Category   // Reference to the parent category, if any
-	Children    []*Category // List of child categories
+	ID                   string        // Unique identifier for the category
+	Name                 string        // Name of the category
+	Description          string        // Description of the category
+	Parent               *Category     // Reference to the parent category, if any
+	Children             []*Category   // List of child categories
+	Slug                 string        `json:",omitempty"` // URL-friendly form of the name, set by the slug enricher
+	Summary              string        `json:",omitempty"` // Beginning of the plain-text description, set by the normalize enricher
+	RawDescription       string        `json:",omitempty"` // Description as written in the CMS editor, kept by the normalize enricher on request, not searchable
+	Language             string        `json:",omitempty"` // Language detected in the name and description, set by the languages enricher
+	LocalizedName        LocalizedText `json:",omitempty"` // Name by language, set by the languages enricher
+	LocalizedDescription LocalizedText `json:",omitempty"` // Description by language, set by the languages enricher
+	Version              int64         `json:"-"`          // Version of the category, derived from its last update, not indexed
 }
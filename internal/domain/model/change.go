@@ -0,0 +1,15 @@
+package model
+
+// Change operations.
+const (
+	ChangeUpsert = "upsert" // The entity was created or updated
+	ChangeDelete = "delete" // The entity was deleted
+)
+
+// Change is a row change captured from the database.
+type Change struct {
+	Operation string      // Operation applied to the entity
+	DocsType  string      // Docs type of the entity
+	ID        string      // Identifier of the entity
+	Document  interface{} // Document of the entity after the change, for upserts
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Command types.
+const (
+	CommandEntityUpserted   = "entity.upserted"   // An entity was created or updated, its document must be indexed
+	CommandEntityDeleted    = "entity.deleted"    // An entity was deleted, its document must be removed
+	CommandReindexRequested = "reindex.requested" // A docs type must be reindexed
+)
+
+// ErrInvalidCommand is returned when a command cannot be processed, however many times it is retried.
+var ErrInvalidCommand = errors.New("invalid command")
+
+// Command is an indexation request received from a message queue.
+type Command struct {
+	Type     string     `json:"type"`            // Type of the command
+	DocsType string     `json:"docsType"`        // Docs type concerned by the command
+	ID       string     `json:"id,omitempty"`    // Identifier of the entity, for entity commands
+	Mode     string     `json:"mode,omitempty"`  // Indexation mode of a reindex, full by default
+	Since    *time.Time `json:"since,omitempty"` // Lower bound of the documents written by a delta reindex
+}
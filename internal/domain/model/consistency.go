@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// ConsistencyReport lists the differences between the entities of a docs type in the database
+// and the documents of its latest index.
+type ConsistencyReport struct {
+	DocsType  string         `json:"docsType"`         // Docs type checked
+	IndexName string         `json:"indexName"`        // Name of the latest index of the docs type
+	Content   bool           `json:"content"`          // Whether the content of the documents was compared, not only their identifiers
+	Entities  int            `json:"entities"`         // Number of entities in the database
+	Documents int            `json:"documents"`        // Number of documents in the index
+	Missing   []string       `json:"missing"`          // Identifiers of the entities without document
+	Extra     []string       `json:"extra"`            // Identifiers of the documents without entity
+	Differing []string       `json:"differing"`        // Identifiers of the documents whose content differs from their entity
+	Repair    *RepairOutcome `json:"repair,omitempty"` // Outcome of the repair of the differences, if requested
+	CheckedAt time.Time      `json:"checkedAt"`        // Time the check was performed
+}
+
+// Consistent reports whether the index holds exactly the documents of the entities.
+func (r ConsistencyReport) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Differing) == 0
+}
+
+// RepairOutcome holds the counters of the repair of the differences between the database and an index.
+type RepairOutcome struct {
+	Indexed     int      `json:"indexed"`      // Missing and differing documents written into the index
+	Stale       int      `json:"staleSkipped"` // Missing documents skipped because a fresher one was indexed in the meantime
+	Removed     int      `json:"removed"`      // Extra documents removed from the index
+	NotRepaired []string `json:"notRepaired"`  // Identifiers of the missing and differing documents not written into the index
+}
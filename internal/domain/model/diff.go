@@ -0,0 +1,33 @@
+package model
+
+// GenerationDiff lists the changes between two generations of the index of a docs type.
+type GenerationDiff struct {
+	DocsType  string          `json:"docsType"`  // Docs type compared
+	From      IndexGeneration `json:"from"`      // Generation compared from
+	To        IndexGeneration `json:"to"`        // Generation compared to
+	Added     []string        `json:"added"`     // Identifiers of the documents only in the generation compared to
+	Removed   []string        `json:"removed"`   // Identifiers of the documents only in the generation compared from
+	Modified  []string        `json:"modified"`  // Identifiers of the documents whose content changed
+	Unchanged int             `json:"unchanged"` // Number of documents whose content did not change
+	Samples   []DocumentDiff  `json:"samples"`   // Field-level differences of a sample of the modified documents
+}
+
+// IndexGeneration identifies a generation of the index of a docs type.
+type IndexGeneration struct {
+	Alias     string `json:"alias"`     // Alias designating the generation (latest, previous or in-progress)
+	IndexName string `json:"indexName"` // Name of the index holding the alias
+	Documents int    `json:"documents"` // Number of documents of the index
+}
+
+// DocumentDiff lists the fields of a document whose value changed between two generations.
+type DocumentDiff struct {
+	ID     string      `json:"id"`     // Identifier of the document
+	Fields []FieldDiff `json:"fields"` // Changed fields, by path
+}
+
+// FieldDiff describes the change of the value of a field. A nil value stands for a field that is missing or null.
+type FieldDiff struct {
+	Field string      `json:"field"` // Path of the field, nested fields being separated by dots
+	From  interface{} `json:"from"`  // Value in the generation compared from
+	To    interface{} `json:"to"`    // Value in the generation compared to
+}
@@ -0,0 +1,54 @@
+// Package model defines the data structures for the application domain.
+package model
+
+// Document is implemented by the domain models that are indexed under their own identifier,
+// so that a later indexation of the same entity overwrites its document instead of duplicating it.
+type Document interface {
+	DocumentID() string
+}
+
+// Versioned is implemented by the documents carrying the version of their entity, which increases with each update.
+// The search engine rejects the write of a document whose version is not greater than the indexed one, so that
+// a stale write never overwrites a fresher document. A zero version is not checked.
+type Versioned interface {
+	DocumentVersion() int64
+}
+
+// DocumentID returns the identifier of the wall document.
+func (w Wall) DocumentID() string { return w.ID }
+
+// DocumentID returns the identifier of the category document.
+func (c Category) DocumentID() string { return c.ID }
+
+// DocumentID returns the identifier of the tag document.
+func (t Tag) DocumentID() string { return t.ID }
+
+// DocumentID returns the identifier of the block document.
+func (b Block) DocumentID() string { return b.ID }
+
+// DocumentID returns the identifier of the program document.
+func (p Program) DocumentID() string { return p.ID }
+
+// DocumentID returns the identifier of the episode document.
+func (e Episode) DocumentID() string { return e.ID }
+
+// DocumentID returns the identifier of the media document.
+func (m Media) DocumentID() string { return m.ID }
+
+// DocumentVersion returns the version of the wall document.
+func (w Wall) DocumentVersion() int64 { return w.Version }
+
+// DocumentVersion returns the version of the category document.
+func (c Category) DocumentVersion() int64 { return c.Version }
+
+// DocumentVersion returns the version of the tag document.
+func (t Tag) DocumentVersion() int64 { return t.Version }
+
+// DocumentVersion returns the version of the block document.
+func (b Block) DocumentVersion() int64 { return b.Version }
+
+// DocumentVersion returns the version of the program document.
+func (p Program) DocumentVersion() int64 { return p.Version }
+
+// DocumentVersion returns the version of the episode document.
+func (e Episode) DocumentVersion() int64 { return e.Version }
@@ -4,10 +4,18 @@ package model
 // Episode represents an episode entity in the system.
 // An Episode is a part of a Program and contains media content.
 type Episode struct {
-	ID          string // Unique identifier for the episode
-	Name        string // Name of the episode
-	Description string // Description of the episode
-	Position    int    // Position of the episode within its program
-	Media       Media  // Media content associated with the episode
-	ProgramID   string // Unique identifier for the associated program
+	ID                   string        // Unique identifier for the episode
+	Name                 string        // Name of the episode
+	Description          string        // Description of the episode
+	Position             int           // Position of the episode within its program
+	Media                Media         // Media content associated with the episode
+	ProgramID            string        // Unique identifier for the associated program
+	Slug                 string        `json:",omitempty"` // URL-friendly form of the name, set by the slug enricher
+	Summary              string        `json:",omitempty"` // Beginning of the plain-text description, set by the normalize enricher
+	RawDescription       string        `json:",omitempty"` // Description as written in the CMS editor, kept by the normalize enricher on request, not searchable
+	Language             string        `json:",omitempty"` // Language detected in the name and description, set by the languages enricher
+	LocalizedName        LocalizedText `json:",omitempty"` // Name by language, set by the languages enricher
+	LocalizedDescription LocalizedText `json:",omitempty"` // Description by language, set by the languages enricher
+	WordCount            *int          `json:",omitempty"` // Number of words of the description, set by the word-count enricher
+	Version              int64         `json:"-"`          // Version of the episode, derived from its last update, not indexed
 }
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// IndexEventVersion is the version of the schema of the index events, incremented on breaking changes.
+const IndexEventVersion = 1
+
+// Index event types.
+const (
+	IndexEventPromoted   = "index.promoted"    // A new index went live under the latest alias
+	IndexEventRolledBack = "index.rolled_back" // A failed promotion was undone, the latest alias is back on its index
+	IndexEventFailed     = "indexation.failed" // A full indexation stopped on an error or timed out
+)
+
+// IndexEvent is an event of the rotation of the indexes, published to the message broker.
+type IndexEvent struct {
+	Version    int            `json:"version"`    // Version of the schema of the event
+	ID         string         `json:"id"`         // Unique identifier for the event
+	Type       string         `json:"type"`       // Type of the event
+	OccurredAt time.Time      `json:"occurredAt"` // Time the event occurred
+	Data       IndexEventData `json:"data"`       // Payload of the event
+}
+
+// IndexEventData is the payload of an index event.
+type IndexEventData struct {
+	JobID             string `json:"jobId,omitempty"`             // Identifier of the job, if the indexation ran as one
+	DocsType          string `json:"docsType"`                    // Docs type of the index
+	IndexName         string `json:"indexName"`                   // Index built by the indexation
+	LatestIndexName   string `json:"latestIndexName,omitempty"`   // Index holding the latest alias after the event
+	PreviousIndexName string `json:"previousIndexName,omitempty"` // Index holding the latest alias before the event
+	Documents         int    `json:"documents"`                   // Documents loaded for the index
+	Error             string `json:"error,omitempty"`             // Error that stopped the indexation, if any
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Referential integrity checks performed across the entities.
+const (
+	IntegrityEpisodeWithoutProgram  = "episode-without-program"  // Episodes whose program does not exist
+	IntegrityMediaWithoutEpisode    = "media-without-episode"    // Medias whose episode does not exist
+	IntegrityCategoryWithoutParent  = "category-without-parent"  // Categories whose parent category does not exist
+	IntegrityProgramWithoutEpisodes = "program-without-episodes" // Programs having no episode
+)
+
+// IntegrityReport lists the entities breaking the references between the entities of the database.
+type IntegrityReport struct {
+	Checks    []IntegrityCheck `json:"checks"`    // Outcome of every check, in a fixed order
+	CheckedAt time.Time        `json:"checkedAt"` // Time the checks were performed
+}
+
+// Consistent reports whether no entity breaks a reference.
+func (r IntegrityReport) Consistent() bool {
+	for _, check := range r.Checks {
+		if len(check.Orphans) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IntegrityCheck holds the entities found by a referential integrity check.
+type IntegrityCheck struct {
+	Name     string   `json:"name"`     // Name of the check
+	DocsType string   `json:"docsType"` // Docs type of the entities checked
+	Checked  int      `json:"checked"`  // Number of entities checked
+	Orphans  []Orphan `json:"orphans"`  // Entities breaking the reference
+}
+
+// Orphan is an entity breaking a reference to another entity.
+type Orphan struct {
+	ID          string `json:"id"`                    // Identifier of the entity
+	ReferenceID string `json:"referenceId,omitempty"` // Identifier of the missing entity it references, if any
+}
@@ -0,0 +1,10 @@
+// Package model defines the data structures for the application domain.
+package model
+
+// DocumentIssue represents a problem found on a document before it is indexed.
+// An empty DocumentID means the issue concerns the docs type as a whole.
+type DocumentIssue struct {
+	DocumentID string `json:"documentId,omitempty"` // Identifier of the offending document
+	Field      string `json:"field,omitempty"`      // Field the issue relates to, if any
+	Reason     string `json:"reason"`               // Human readable description of the issue
+}
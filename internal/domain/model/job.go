@@ -0,0 +1,89 @@
+// Package model defines the data structures for the application domain.
+package model
+
+import "time"
+
+// Job statuses.
+const (
+	JobRunning   = "running"   // The indexation is executing
+	JobSucceeded = "succeeded" // The indexation completed
+	JobFailed    = "failed"    // The indexation stopped on an error
+	JobCancelled = "cancelled" // The indexation was cancelled before completion
+)
+
+// Job failure reasons.
+const (
+	ReasonError   = "error"   // The indexation stopped on an error
+	ReasonTimeout = "timeout" // The indexation exceeded the deadline of its docs type
+)
+
+// Job triggers.
+const (
+	TriggerAPI       = "api"       // The indexation was requested through the HTTP api
+	TriggerScheduler = "scheduler" // The indexation was triggered by the scheduler
+	TriggerMessage   = "message"   // The indexation was requested through the message queue
+)
+
+// Job represents a single indexation run of a docs type.
+type Job struct {
+	ID         string             `json:"id"`                   // Unique identifier for the job
+	DocsType   string             `json:"docsType"`             // Docs type being indexed
+	Mode       string             `json:"mode"`                 // Indexation mode (full, delta or reconcile)
+	Trigger    string             `json:"trigger"`              // Source that triggered the job
+	Caller     string             `json:"caller,omitempty"`     // Identity of the caller that requested the job, if any
+	IndexName  string             `json:"indexName,omitempty"`  // Name of the index written by the job
+	Status     string             `json:"status"`               // Current status of the job
+	Reason     string             `json:"reason,omitempty"`     // Reason of the failure of a failed job
+	Error      string             `json:"error,omitempty"`      // Error that stopped the job, if any
+	Progress   Progress           `json:"progress"`             // Advancement of the job
+	Quality    []QualityViolation `json:"quality,omitempty"`    // Data-quality rules violated by the documents of the job
+	StartedAt  time.Time          `json:"startedAt"`            // Time the job started
+	FinishedAt *time.Time         `json:"finishedAt,omitempty"` // Time the job finished, if it did
+}
+
+// Finished reports whether the job is no longer running.
+func (j Job) Finished() bool {
+	return j.Status != JobRunning
+}
+
+// JobFilter selects jobs from the indexation history. Empty fields do not filter.
+type JobFilter struct {
+	DocsType string    // Docs type of the jobs
+	Mode     string    // Indexation mode of the jobs
+	Status   string    // Status of the jobs
+	Trigger  string    // Source that triggered the jobs
+	Caller   string    // Identity of the caller that requested the jobs
+	From     time.Time // Lower bound of the start time of the jobs
+	To       time.Time // Upper bound, excluded, of the start time of the jobs
+	Limit    int       // Maximum number of jobs returned
+	Offset   int       // Number of matching jobs skipped
+}
+
+// Matches reports whether the job is selected by the filter, regardless of the pagination.
+func (f JobFilter) Matches(job Job) bool {
+	switch {
+	case f.DocsType != "" && job.DocsType != f.DocsType:
+		return false
+	case f.Mode != "" && job.Mode != f.Mode:
+		return false
+	case f.Status != "" && job.Status != f.Status:
+		return false
+	case f.Trigger != "" && job.Trigger != f.Trigger:
+		return false
+	case f.Caller != "" && job.Caller != f.Caller:
+		return false
+	case !f.From.IsZero() && job.StartedAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && !job.StartedAt.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// JobPage is a page of jobs from the indexation history, newest first.
+type JobPage struct {
+	Jobs   []Job `json:"jobs"`   // Jobs of the page
+	Total  int   `json:"total"`  // Number of jobs matching the filter
+	Limit  int   `json:"limit"`  // Maximum number of jobs of the page
+	Offset int   `json:"offset"` // Number of matching jobs skipped before the page
+}
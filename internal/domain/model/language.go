@@ -0,0 +1,13 @@
+package model
+
+// Languages whose analyzers are available for the localized fields.
+const (
+	LanguageFrench  = "fr"
+	LanguageEnglish = "en"
+)
+
+// LocalizedText holds the text of a field by language, each language being searched with its own analyzer.
+// It is indexed as a separate field, e.g. LocalizedName.fr and LocalizedName.en, rather than as name.fr and name.en
+// multi-fields of the original field: a multi-field always indexes the text of its field in every language, while
+// language detection must populate the detected language only.
+type LocalizedText map[string]string
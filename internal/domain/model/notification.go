@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// Notification types.
+const (
+	NotificationSucceeded = "indexation.succeeded" // An indexation job completed
+	NotificationFailed    = "indexation.failed"    // An indexation job stopped on an error or timed out
+	NotificationPromoted  = "index.promoted"       // A new index went live under the latest alias
+)
+
+// Notification describes the outcome of an indexation job to external consumers.
+type Notification struct {
+	ID         string             `json:"id"`                  // Unique identifier for the notification
+	Type       string             `json:"type"`                // Type of the notification
+	OccurredAt time.Time          `json:"occurredAt"`          // Time the notification was emitted
+	JobID      string             `json:"jobId"`               // Identifier of the job
+	DocsType   string             `json:"docsType"`            // Docs type indexed by the job
+	Mode       string             `json:"mode"`                // Indexation mode (full, delta or reconcile)
+	Trigger    string             `json:"trigger"`             // Source that triggered the job
+	IndexName  string             `json:"indexName,omitempty"` // Name of the index written by the job
+	Status     string             `json:"status"`              // Final status of the job
+	Reason     string             `json:"reason,omitempty"`    // Reason of the failure of a failed job
+	Error      string             `json:"error,omitempty"`     // Error that stopped the job, if any
+	Counts     NotificationCounts `json:"counts"`              // Document counters of the job
+	DurationMs int64              `json:"durationMs"`          // Duration of the job in milliseconds
+}
+
+// NotificationCounts holds the document counters of a notified job.
+type NotificationCounts struct {
+	Documents int `json:"documents"`    // Documents loaded from the database
+	Invalid   int `json:"invalid"`      // Documents not matching the mapping
+	Dropped   int `json:"dropped"`      // Documents not indexed because they violate a data-quality rule
+	Indexed   int `json:"indexed"`      // Documents accepted by the search engine
+	Failures  int `json:"failures"`     // Documents rejected by the search engine
+	Removed   int `json:"removed"`      // Documents of deleted entities removed from the index
+	Stale     int `json:"staleSkipped"` // Documents skipped because a fresher version is indexed
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"   // The delivery is being attempted
+	DeliveryDelivered = "delivered" // The receiver acknowledged the notification
+	DeliveryFailed    = "failed"    // Every attempt failed
+)
+
+// WebhookDelivery records the delivery of a notification to a webhook.
+type WebhookDelivery struct {
+	ID             string     `json:"id"`                   // Unique identifier for the delivery
+	NotificationID string     `json:"notificationId"`       // Identifier of the delivered notification
+	Type           string     `json:"type"`                 // Type of the delivered notification
+	URL            string     `json:"url"`                  // URL of the webhook
+	Status         string     `json:"status"`               // Status of the delivery
+	Attempts       int        `json:"attempts"`             // Number of attempts made
+	StatusCode     int        `json:"statusCode,omitempty"` // HTTP status of the last response, if any
+	Error          string     `json:"error,omitempty"`      // Error of the last attempt, if it failed
+	StartedAt      time.Time  `json:"startedAt"`            // Time of the first attempt
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"` // Time the delivery completed, if it did
+}
@@ -4,8 +4,17 @@ package model
 // Program represents a program entity in the system.
 // A Program is a collection of episodes and contains metadata about the program.
 type Program struct {
-	ID          string    // Unique identifier for the program
-	Name        string    // Name of the program
-	Description string    // Description of the program
-	Episodes    []Episode // List of episodes associated with the program
+	ID                   string        // Unique identifier for the program
+	Name                 string        // Name of the program
+	Description          string        // Description of the program
+	Episodes             []Episode     // List of episodes associated with the program
+	Slug                 string        `json:",omitempty"` // URL-friendly form of the name, set by the slug enricher
+	Summary              string        `json:",omitempty"` // Beginning of the plain-text description, set by the normalize enricher
+	RawDescription       string        `json:",omitempty"` // Description as written in the CMS editor, kept by the normalize enricher on request, not searchable
+	Language             string        `json:",omitempty"` // Language detected in the name and description, set by the languages enricher
+	LocalizedName        LocalizedText `json:",omitempty"` // Name by language, set by the languages enricher
+	LocalizedDescription LocalizedText `json:",omitempty"` // Description by language, set by the languages enricher
+	EpisodeCount         *int          `json:",omitempty"` // Number of episodes of the program, set by the episode-count enricher
+	WordCount            *int          `json:",omitempty"` // Number of words of the description, set by the word-count enricher
+	Version              int64         `json:"-"`          // Version of the program, derived from its last update, not indexed
 }
@@ -0,0 +1,48 @@
+// Package model defines the data structures for the application domain.
+package model
+
+// Indexation phases.
+const (
+	PhaseCreate   = "create"   // The new index is being created
+	PhaseLoad     = "load"     // The documents are being read from the database
+	PhaseValidate = "validate" // The documents are being checked against the data-quality rules and the mapping
+	PhaseIndex    = "index"    // The documents are being written into the index
+	PhaseRemove   = "remove"   // The documents of deleted entities are being removed from the index
+	PhasePromote  = "promote"  // The aliases are being rotated
+)
+
+// Job event types.
+const (
+	EventSnapshot = "snapshot" // Current state of the job, sent first to every subscriber
+	EventPhase    = "phase"    // The job entered a new phase
+	EventProgress = "progress" // The counters of the job changed
+	EventFinished = "finished" // The job finished, no event follows
+)
+
+// Progress represents the advancement of an indexation job.
+type Progress struct {
+	Phase     string `json:"phase,omitempty"` // Current phase of the job
+	RowsRead  int    `json:"rowsRead"`        // Number of rows read from the database
+	Documents int    `json:"documents"`       // Number of documents built from the rows
+	Invalid   int    `json:"invalid"`         // Number of documents not matching the mapping
+	Dropped   int    `json:"dropped"`         // Number of documents not indexed because they violate a data-quality rule
+	Flushes   int    `json:"flushes"`         // Number of bulk requests sent
+	Indexed   int    `json:"indexed"`         // Number of documents written into the index
+	Failures  int    `json:"failures"`        // Number of documents rejected by the index
+	Removed   int    `json:"removed"`         // Number of documents of deleted entities removed from the index
+	Stale     int    `json:"staleSkipped"`    // Number of documents skipped because a fresher version is indexed
+}
+
+// JobEvent represents a change in the state of an indexation job.
+type JobEvent struct {
+	Type string `json:"type"` // Type of the event
+	Job  Job    `json:"job"`  // State of the job after the change
+}
+
+// BulkResult represents the outcome of a bulk request.
+type BulkResult struct {
+	Indexed    int      // Number of documents written
+	Failed     int      // Number of documents rejected
+	Stale      int      // Number of documents skipped because a fresher version is indexed
+	NotWritten []string // Identifiers of the stale and rejected documents
+}
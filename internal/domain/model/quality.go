@@ -0,0 +1,16 @@
+package model
+
+// Data-quality rule severities.
+const (
+	SeverityDrop = "drop" // The violating documents are not indexed
+	SeverityWarn = "warn" // The violating documents are indexed and reported
+	SeverityFail = "fail" // The run fails before indexing anything
+)
+
+// QualityViolation reports the entities violating a data-quality rule.
+type QualityViolation struct {
+	Rule      string   `json:"rule"`      // Name of the rule, prefixed with its docs type
+	Severity  string   `json:"severity"`  // Severity of the rule
+	Message   string   `json:"message"`   // Description of the violation
+	EntityIDs []string `json:"entityIds"` // Identifiers of the violating entities
+}
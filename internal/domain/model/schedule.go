@@ -0,0 +1,21 @@
+// Package model defines the data structures for the application domain.
+package model
+
+import "time"
+
+// Schedule represents the state of the periodic reindexing scheduler.
+type Schedule struct {
+	Enabled bool           `json:"enabled"` // Whether scheduled runs are currently triggered
+	Leader  bool           `json:"leader"`  // Whether this instance is the one triggering scheduled runs
+	Runs    []ScheduledRun `json:"runs"`    // Configured scheduled runs
+}
+
+// ScheduledRun represents a periodic indexation of a docs type.
+type ScheduledRun struct {
+	DocsType    string     `json:"docsType"`              // Docs type indexed by the run
+	Mode        string     `json:"mode"`                  // Indexation mode (full, delta or reconcile)
+	Cron        string     `json:"cron"`                  // Cron expression triggering the run
+	Running     bool       `json:"running"`               // Whether the run is currently executing
+	PreviousRun *time.Time `json:"previousRun,omitempty"` // Time the run was last triggered, if any
+	NextRun     time.Time  `json:"nextRun"`               // Time the run will next be triggered
+}
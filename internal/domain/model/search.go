@@ -0,0 +1,79 @@
+package model
+
+import "encoding/json"
+
+// Suggestion is a document whose name completes the words typed in a search bar.
+type Suggestion struct {
+	ID    string  `json:"id"`    // Identifier of the document
+	Text  string  `json:"text"`  // Name of the document
+	Score float64 `json:"score"` // Relevance of the suggestion, higher first
+}
+
+// SuggestionGroup holds the suggestions of a docs type, best first.
+type SuggestionGroup struct {
+	DocsType    string       `json:"docsType"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Suggestions are the suggestions completing a query, grouped by docs type.
+type Suggestions struct {
+	Query  string            `json:"query"`
+	Groups []SuggestionGroup `json:"groups"`
+}
+
+// Sorts of the search results.
+const (
+	SortRelevance    = "relevance"     // Best matches first
+	SortName         = "name"          // Alphabetical order of the names
+	SortPosition     = "position"      // Position of the episodes within their program
+	SortEpisodeCount = "episode-count" // Number of episodes of the programs
+)
+
+// Orders of the search results.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// SearchQuery is a search of the documents of a docs type in its latest index.
+type SearchQuery struct {
+	Text      string // Words searched in the names and descriptions, every document matches if empty
+	ProgramID string // Keeps the episodes of the program, if set
+	Kind      string // Keeps the medias, or the episodes whose media, is of the kind, if set
+	Sort      string // Sort of the results, see SortRelevance
+	Order     string // Order of the sort, see OrderAsc and OrderDesc
+	Highlight bool   // Whether the matching fragments of the text are returned
+	Limit     int    // Maximum number of documents of the page
+	Offset    int    // Number of matching documents skipped before the page
+}
+
+// SearchHit is a document matching a search.
+type SearchHit struct {
+	ID         string              `json:"id"`                   // Identifier of the document
+	Score      float64             `json:"score"`                // Relevance of the document, zero when not sorted by relevance
+	Document   json.RawMessage     `json:"document"`             // Document as indexed
+	Highlights map[string][]string `json:"highlights,omitempty"` // Matching fragments by field, the matching words being wrapped in <em> tags
+}
+
+// SearchResult is a page of the documents matching a search.
+type SearchResult struct {
+	DocsType string      `json:"docsType"`
+	Hits     []SearchHit `json:"hits"`   // Documents of the page
+	Total    int         `json:"total"`  // Number of documents matching the search
+	Limit    int         `json:"limit"`  // Maximum number of documents of the page
+	Offset   int         `json:"offset"` // Number of matching documents skipped before the page
+}
+
+// FederatedHit is a hit of the blended top hits of a federated search.
+type FederatedHit struct {
+	DocsType string `json:"docsType"`
+	SearchHit
+	BoostedScore float64 `json:"boostedScore"` // Score multiplied by the boost of the docs type, ranking the top hits
+}
+
+// FederatedResult holds the documents of several docs types matching a search, per docs type and blended.
+type FederatedResult struct {
+	Query   string         `json:"query"`
+	Groups  []SearchResult `json:"groups"`  // Best documents of each docs type, with the number of matching documents
+	TopHits []FederatedHit `json:"topHits"` // Best documents of all docs types, by boosted score
+}
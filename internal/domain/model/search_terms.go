@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// SearchTerms are the synonyms and stopwords managed by the editors, applied by the analyzers of the indexes.
+type SearchTerms struct {
+	Synonyms  []string  `json:"synonyms"`  // Synonym rules in the Solr format, e.g. "pod, podcast" or "actu => actualités"
+	Stopwords []string  `json:"stopwords"` // Words ignored by the analyzers
+	UpdatedAt time.Time `json:"updatedAt"` // Time of the last update
+}
+
+// SearchTermsUpdate is the outcome of an update of the search terms.
+type SearchTermsUpdate struct {
+	SearchTerms
+	Reloaded []string `json:"reloaded"` // Names of the live indexes whose search analyzers were updated, if requested
+}
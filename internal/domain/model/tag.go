@@ -4,7 +4,14 @@ package model
 // Tag represents a tag entity in the system.
 // A Tag is used to categorize or label programs.
 type Tag struct {
-	ID          string // Unique identifier for the tag
-	Name        string // Name of the tag
-	Description string // Description of the tag
+	ID                   string        // Unique identifier for the tag
+	Name                 string        // Name of the tag
+	Description          string        // Description of the tag
+	Slug                 string        `json:",omitempty"` // URL-friendly form of the name, set by the slug enricher
+	Summary              string        `json:",omitempty"` // Beginning of the plain-text description, set by the normalize enricher
+	RawDescription       string        `json:",omitempty"` // Description as written in the CMS editor, kept by the normalize enricher on request, not searchable
+	Language             string        `json:",omitempty"` // Language detected in the name and description, set by the languages enricher
+	LocalizedName        LocalizedText `json:",omitempty"` // Name by language, set by the languages enricher
+	LocalizedDescription LocalizedText `json:",omitempty"` // Description by language, set by the languages enricher
+	Version              int64         `json:"-"`          // Version of the tag, derived from its last update, not indexed
 }
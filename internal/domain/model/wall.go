@@ -4,8 +4,15 @@ package model
 // Wall represents a wall entity in the system.
 // A Wall is a collection of blocks, each containing content and organizational metadata.
 type Wall struct {
-	ID          string  // Unique identifier for the wall
-	Name        string  // Name of the wall
-	Description string  // Description of the wall
-	Blocks      []Block // List of blocks associated with the wall
+	ID                   string        // Unique identifier for the wall
+	Name                 string        // Name of the wall
+	Description          string        // Description of the wall
+	Blocks               []Block       // List of blocks associated with the wall
+	Slug                 string        `json:",omitempty"` // URL-friendly form of the name, set by the slug enricher
+	Summary              string        `json:",omitempty"` // Beginning of the plain-text description, set by the normalize enricher
+	RawDescription       string        `json:",omitempty"` // Description as written in the CMS editor, kept by the normalize enricher on request, not searchable
+	Language             string        `json:",omitempty"` // Language detected in the name and description, set by the languages enricher
+	LocalizedName        LocalizedText `json:",omitempty"` // Name by language, set by the languages enricher
+	LocalizedDescription LocalizedText `json:",omitempty"` // Description by language, set by the languages enricher
+	Version              int64         `json:"-"`          // Version of the wall, derived from its last update, not indexed
 }
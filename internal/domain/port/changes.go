@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// ChangeHandler applies a change captured from the database.
+type ChangeHandler func(ctx context.Context, change model.Change) error
+
+// ChangeStream abstracts a change data capture source of the database.
+type ChangeStream interface {
+	// Stream passes the captured changes to the handler, in commit order, until the context is done or the handler fails.
+	// The position in the source is checkpointed after the changes of each transaction are handled, so that a new
+	// stream resumes after the last checkpoint: changes handled after it are passed again.
+	Stream(ctx context.Context, handler ChangeHandler) error
+}
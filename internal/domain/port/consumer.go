@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// CommandHandler processes a command received from a message queue.
+type CommandHandler func(ctx context.Context, command model.Command) error
+
+// Consumer abstracts an inbound message queue delivering indexation commands.
+type Consumer interface {
+	// Consume passes the received commands to the handler until the context is done.
+	// A command is acknowledged once handled and redelivered after a failure. It is sent to a dead-letter
+	// destination when it is malformed, when the handler returns model.ErrInvalidCommand or when its
+	// deliveries are exhausted.
+	Consume(ctx context.Context, handler CommandHandler) error
+}
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// History abstracts the durable record of the indexation jobs.
+type History interface {
+	// Save inserts the job, or replaces its previous record.
+	Save(ctx context.Context, job model.Job) error
+	// Find returns the page of jobs selected by the filter, newest first.
+	Find(ctx context.Context, filter model.JobFilter) (model.JobPage, error)
+}
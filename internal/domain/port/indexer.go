@@ -1,14 +1,25 @@
 package port
 
-import "context"
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
 
 // Indexer is an interface that abstracts the functionalities of the Elasticsearch client.
 type Indexer interface {
-	CreateIndex(ctx context.Context, indexName string, docsType string) error
+	CreateIndex(ctx context.Context, indexName string, docsType string, terms model.SearchTerms) error
+	ReloadSearchTerms(ctx context.Context, indexName string, docsType string, terms model.SearchTerms) error
 	DeleteIndexes(ctx context.Context, indexNames []string) error
 	CreateAlias(ctx context.Context, indexName, aliasName string) error
 	DeleteAlias(ctx context.Context, indexName, aliasName string) error
 	IndexByAlias(ctx context.Context, aliasName string) []string
 	MoveIndex(ctx context.Context, indexationName string) error
-	RecordBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) error
+	DeleteDocument(ctx context.Context, indexName, documentID string) error
+	DeleteDocuments(ctx context.Context, indexName string, documentIDs []string) (int, error)
+	DocumentIDs(ctx context.Context, indexName string) ([]string, error)
+	DocumentSources(ctx context.Context, indexName string) (map[string][]byte, error)
+	Documents(ctx context.Context, indexName string, documentIDs []string) (map[string][]byte, error)
+	RecordBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error)
+	OverwriteBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error)
+	ValidateDocuments(ctx context.Context, docsType string, items []interface{}) ([]model.DocumentIssue, error)
 }
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+// Lease abstracts a named lease that can be held by a single holder at a time.
+type Lease interface {
+	// Acquire takes the lease if it is free or expired, or renews it if the holder already owns it.
+	// It returns whether the holder owns the lease for the given duration.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if it is owned by the holder.
+	Release(ctx context.Context, name, holder string) error
+}
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// Notifier abstracts the publication of indexation notifications to external consumers.
+type Notifier interface {
+	// Notify publishes the notification. Implementations may deliver it asynchronously.
+	Notify(ctx context.Context, notification model.Notification) error
+}
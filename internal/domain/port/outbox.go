@@ -0,0 +1,12 @@
+package port
+
+import "context"
+
+// Outbox abstracts a transactional outbox filled by the services writing the entities.
+type Outbox interface {
+	// Process passes the oldest pending entries, up to limit, to the handler as entity commands.
+	// Handled entries are marked processed, failed ones stay pending until maxAttempts failures.
+	// Entries are locked while being processed, so concurrent callers process distinct entries.
+	// It returns the number of entries passed to the handler.
+	Process(ctx context.Context, limit, maxAttempts int, handler CommandHandler) (int, error)
+}
@@ -2,32 +2,56 @@ package port
 
 import (
 	"context"
+	"errors"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"time"
 )
 
+// ErrNotFound is returned when looking up an entity that does not exist.
+var ErrNotFound = errors.New("not found")
+
 type Wall interface {
 	FindAll(ctx context.Context) ([]*model.Wall, error)
+	Find(ctx context.Context, id string) (*model.Wall, error)
+	FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Wall, error)
 }
 
 type Category interface {
 	FindAll(ctx context.Context) ([]*model.Category, error)
+	Find(ctx context.Context, id string) (*model.Category, error)
+	FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Category, error)
 }
 type Tag interface {
 	FindAll(ctx context.Context) ([]*model.Tag, error)
+	Find(ctx context.Context, id string) (*model.Tag, error)
+	FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Tag, error)
 }
 
 type Block interface {
 	FindAll(ctx context.Context) ([]*model.Block, error)
+	Find(ctx context.Context, id string) (*model.Block, error)
+	FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Block, error)
 }
 
 type Program interface {
 	FindAll(ctx context.Context) ([]*model.Program, error)
+	Find(ctx context.Context, id string) (*model.Program, error)
+	FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Program, error)
+	FindDeletedSince(ctx context.Context, since time.Time) ([]string, error)
+	FindIDs(ctx context.Context) ([]string, error)
+	FindExistingIDs(ctx context.Context, ids []string) ([]string, error)
 }
 
 type Episode interface {
 	FindAll(ctx context.Context) ([]*model.Episode, error)
+	Find(ctx context.Context, id string) (*model.Episode, error)
+	FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Episode, error)
+	FindDeletedSince(ctx context.Context, since time.Time) ([]string, error)
+	FindIDs(ctx context.Context) ([]string, error)
+	CountByProgram(ctx context.Context, programIDs []string) (map[string]int, error)
 }
 
 type Media interface {
 	FindAll(ctx context.Context) ([]*model.Media, error)
+	Find(ctx context.Context, id string) (*model.Media, error)
 }
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// Publisher abstracts the publication of index events to a message broker.
+type Publisher interface {
+	// Publish sends the event to the broker.
+	Publish(ctx context.Context, event model.IndexEvent) error
+}
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// SearchTerms persists the synonyms and stopwords of the search analyzers.
+type SearchTerms interface {
+	// Find returns the current search terms, empty if none were saved.
+	Find(ctx context.Context) (model.SearchTerms, error)
+	// Save replaces the search terms.
+	Save(ctx context.Context, terms model.SearchTerms) error
+}
@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// Searcher is an interface that abstracts the queries of the latest indexes.
+type Searcher interface {
+	// Suggest returns, per docs type, at most size documents whose name completes the query, best first.
+	// A docs type without latest index has no suggestions.
+	Suggest(ctx context.Context, query string, docsTypes []string, size int) (map[string][]model.Suggestion, error)
+	// Search returns the page of the documents of the docs type matching the query.
+	// A docs type without latest index has no documents.
+	Search(ctx context.Context, docsType string, query model.SearchQuery) (model.SearchResult, error)
+	// SearchAll returns, per docs type, at most size documents matching the text, best first, searched at once.
+	// A docs type without latest index has no documents.
+	SearchAll(ctx context.Context, text string, docsTypes []string, size int) (map[string]model.SearchResult, error)
+}
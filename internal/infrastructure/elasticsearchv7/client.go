@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
@@ -25,9 +27,17 @@ const (
 	inProgressAlias = "in-progress"
 )
 
+const (
+	scrollKeepAlive = time.Minute // Duration the search context of a scroll is kept between two pages
+	scrollPageSize  = 1000        // Number of documents per page of a scroll
+)
+
 // adapter represents an Elasticsearch adapter wrapper.
 type adapter struct {
-	client *elasticsearch.Client
+	client       *elasticsearch.Client
+	languages    map[string][]string // Languages of the localized fields per docs type
+	synonymsFile string              // Local path of the synonyms file read by the search analyzers, if any
+	synonymsPath string              // Path of the same file relative to the configuration directory of Elasticsearch
 }
 
 // NewElasticSearchClient initializes a new Elasticsearch adapter with the given configuration.
@@ -37,19 +47,31 @@ func NewElasticSearchClient(config *configuration.AppConfig) (port.Indexer, erro
 		log.Error().Err(err).Msg("elasticsearch adapter init failed")
 		return nil, fmt.Errorf("elasticsearch adapter init failed: %w", err)
 	}
-	return &adapter{client: esClient}, nil
+	return &adapter{
+		client:       esClient,
+		languages:    config.Indexation.Languages,
+		synonymsFile: config.Elasticsearch.SynonymsFile,
+		synonymsPath: config.Elasticsearch.SynonymsPath,
+	}, nil
 }
 
-// CreateIndex creates a new index with the given name and default settings.
-func (c *adapter) CreateIndex(ctx context.Context, indexName string, docsType string) error {
+// CreateIndex creates a new index with the given name and default settings, its analyzers applying the search terms.
+// The synonyms file, if any, is written first: it must exist when the index is created.
+func (c *adapter) CreateIndex(ctx context.Context, indexName string, docsType string, terms model.SearchTerms) error {
 
-	metadata, err := metadataByDocsType(docsType)
+	synonymsPath := ""
+	if c.synonymsFile != "" {
+		if err := c.writeSynonyms(terms.Synonyms); err != nil {
+			return err
+		}
+		synonymsPath = c.synonymsPath
+	}
+	parsedMetadata, err := indexDefinition(docsType, c.languages[docsType], terms, synonymsPath)
 	if err != nil {
 		log.Error().Err(err).Msg("getting metadata failed")
-		return errors.New("unknown docs type")
+		return fmt.Errorf("could not define index of %s: %w", docsType, err)
 	}
 
-	parsedMetadata := fmt.Sprintf(metadata, 10, 1, 5, 200)
 	body := bytes.NewBufferString(parsedMetadata)
 	response, err := c.client.Indices.Create(indexName, c.client.Indices.Create.WithBody(body), c.client.Indices.Create.WithContext(ctx))
 	if err != nil {
@@ -114,6 +136,193 @@ func (c *adapter) DeleteAlias(ctx context.Context, indexName, aliasName string)
 	return nil
 }
 
+// DeleteDocument deletes the document with the given identifier from the index.
+// Deleting a document that does not exist is not an error.
+func (c *adapter) DeleteDocument(ctx context.Context, indexName, documentID string) error {
+	response, err := c.client.Delete(indexName, documentID, c.client.Delete.WithContext(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("deleting document failed")
+		return err
+	}
+	defer closeBodyResponse(response)
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotFound {
+		log.Error().Interface("response-code", response.StatusCode).Msg("deleting document failed : response code")
+		return errors.New("error while deleting document from index")
+	}
+	return nil
+}
+
+// DeleteDocuments deletes the documents with the given identifiers from the index in a single bulk request
+// and returns the number of documents deleted. Deleting a document that does not exist is not an error.
+func (c *adapter) DeleteDocuments(ctx context.Context, indexName string, documentIDs []string) (int, error) {
+	if len(documentIDs) == 0 {
+		return 0, nil
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, id := range documentIDs {
+		if err := encoder.Encode(map[string]interface{}{"delete": map[string]string{"_index": indexName, "_id": id}}); err != nil {
+			return 0, fmt.Errorf("an error occurred while encoding: %w", err)
+		}
+	}
+	response, err := c.client.Bulk(&buf, c.client.Bulk.WithIndex(indexName), c.client.Bulk.WithContext(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("deleting documents failed")
+		return 0, err
+	}
+	defer closeBodyResponse(response)
+	if response.IsError() {
+		return 0, handleBulkResponseError(response)
+	}
+
+	var result struct {
+		Items []struct {
+			Delete struct {
+				ID     string `json:"_id"`
+				Status int    `json:"status"`
+			} `json:"delete"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failure to parse response body: %s", err)
+	}
+	deleted, failed := 0, 0
+	for _, item := range result.Items {
+		switch item.Delete.Status {
+		case http.StatusOK:
+			deleted++
+		case http.StatusNotFound:
+		default:
+			failed++
+			log.Error().Int("status", item.Delete.Status).Str("id", item.Delete.ID).Msg("Bulk delete error")
+		}
+	}
+	if failed > 0 {
+		return deleted, fmt.Errorf("deleted documents with [%d] errors", failed)
+	}
+	return deleted, nil
+}
+
+// DocumentIDs retrieves the identifiers of all the documents of the index, scrolling through it.
+func (c *adapter) DocumentIDs(ctx context.Context, indexName string) ([]string, error) {
+	var ids []string
+	err := c.scroll(ctx, indexName, false, func(hit scrollHit) {
+		ids = append(ids, hit.ID)
+	})
+	return ids, err
+}
+
+// Documents retrieves the sources of the documents of the index with the given identifiers, by identifier,
+// in a single request. The identifiers without document are left out.
+func (c *adapter) Documents(ctx context.Context, indexName string, documentIDs []string) (map[string][]byte, error) {
+	sources := make(map[string][]byte, len(documentIDs))
+	if len(documentIDs) == 0 {
+		return sources, nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"ids": documentIDs})
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.client.Mget(bytes.NewReader(body), c.client.Mget.WithIndex(indexName), c.client.Mget.WithContext(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("getting documents failed")
+		return nil, err
+	}
+	defer closeBodyResponse(response)
+	if response.StatusCode != http.StatusOK {
+		log.Error().Interface("response-code", response.StatusCode).Msg("getting documents failed : response code")
+		return nil, errors.New("error while getting documents from index")
+	}
+	var result struct {
+		Docs []struct {
+			ID     string          `json:"_id"`
+			Found  bool            `json:"found"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"docs"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failure to parse response body: %s", err)
+	}
+	for _, document := range result.Docs {
+		if document.Found {
+			sources[document.ID] = document.Source
+		}
+	}
+	return sources, nil
+}
+
+// DocumentSources retrieves the source of all the documents of the index by identifier, scrolling through it.
+func (c *adapter) DocumentSources(ctx context.Context, indexName string) (map[string][]byte, error) {
+	sources := make(map[string][]byte)
+	err := c.scroll(ctx, indexName, true, func(hit scrollHit) {
+		sources[hit.ID] = hit.Source
+	})
+	return sources, err
+}
+
+// scroll passes every document of the index to visit, with its source if requested.
+func (c *adapter) scroll(ctx context.Context, indexName string, withSource bool, visit func(hit scrollHit)) error {
+	response, err := c.client.Search(
+		c.client.Search.WithContext(ctx),
+		c.client.Search.WithIndex(indexName),
+		c.client.Search.WithScroll(scrollKeepAlive),
+		c.client.Search.WithSize(scrollPageSize),
+		c.client.Search.WithSort("_doc"),
+		c.client.Search.WithSource(strconv.FormatBool(withSource)),
+	)
+	var scrollID string
+	defer func() {
+		if scrollID != "" {
+			c.clearScroll(scrollID)
+		}
+	}()
+	for {
+		if err != nil {
+			log.Error().Err(err).Msg("scrolling documents failed")
+			return err
+		}
+		var page scrollPage
+		if err := decodeScrollPage(response, &page); err != nil {
+			return err
+		}
+		scrollID = page.ScrollID
+		if len(page.Hits.Hits) == 0 {
+			return nil
+		}
+		for _, hit := range page.Hits.Hits {
+			visit(hit)
+		}
+		response, err = c.client.Scroll(
+			c.client.Scroll.WithContext(ctx),
+			c.client.Scroll.WithScrollID(scrollID),
+			c.client.Scroll.WithScroll(scrollKeepAlive),
+		)
+	}
+}
+
+// clearScroll releases the search context of a scroll.
+func (c *adapter) clearScroll(scrollID string) {
+	response, err := c.client.ClearScroll(c.client.ClearScroll.WithScrollID(scrollID))
+	if err != nil {
+		log.Warn().Err(err).Msg("clearing scroll failed")
+		return
+	}
+	closeBodyResponse(response)
+}
+
+// decodeScrollPage decodes a page of a scroll, closing the response.
+func decodeScrollPage(response *esapi.Response, page *scrollPage) error {
+	defer closeBodyResponse(response)
+	if response.IsError() {
+		log.Error().Interface("response-code", response.StatusCode).Msg("scrolling documents failed : response code")
+		return errors.New("error while scrolling documents")
+	}
+	if err := json.NewDecoder(response.Body).Decode(page); err != nil {
+		return fmt.Errorf("failure to parse response body: %s", err)
+	}
+	return nil
+}
+
 // IndexByAlias retrieves the index names associated with the given alias.
 func (c *adapter) IndexByAlias(ctx context.Context, aliasName string) []string {
 	response, err := c.client.Indices.Get([]string{aliasName}, c.client.Indices.Get.WithContext(ctx))
@@ -179,16 +388,26 @@ func (c *adapter) switchAlias(ctx context.Context, newIndex, alias string, oldIn
 }
 
 // RecordBulkItems indexes a batch of items using bulk indexing with retry logic.
-func (c *adapter) RecordBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) error {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(backoffRetryCount+1)*time.Duration(backoffTimeSeconds)*time.Second)
-	defer cancel()
+// The whole operation, retries included, is bounded by the deadline of the given context.
+// It returns the number of written and rejected documents, and an error if any document was rejected.
+func (c *adapter) RecordBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error) {
+	return c.bulkIndex(ctx, indexName, items, true, backoffRetryCount, backoffTimeSeconds)
+}
 
+// OverwriteBulkItems indexes a batch of items like RecordBulkItems, but without checking their external version:
+// the documents are overwritten even if their version is not greater than the indexed one.
+func (c *adapter) OverwriteBulkItems(ctx context.Context, indexName string, items []interface{}, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error) {
+	return c.bulkIndex(ctx, indexName, items, false, backoffRetryCount, backoffTimeSeconds)
+}
+
+// bulkIndex indexes a batch of items, with their external version if requested.
+func (c *adapter) bulkIndex(ctx context.Context, indexName string, items []interface{}, external bool, backoffRetryCount, backoffTimeSeconds int) (model.BulkResult, error) {
 	var buf bytes.Buffer
 	for _, item := range items {
-		meta := []byte(fmt.Sprintf(`{ "index" : { "_index" : "%s" } }%s`, indexName, "\n"))
+		meta := bulkIndexMeta(indexName, item, external)
 		data, err := json.Marshal(item)
 		if err != nil {
-			return fmt.Errorf("an error occurred while encoding: %w", err)
+			return model.BulkResult{}, fmt.Errorf("an error occurred while encoding: %w", err)
 		}
 		data = append(data, '\n')
 
@@ -197,24 +416,38 @@ func (c *adapter) RecordBulkItems(ctx context.Context, indexName string, items [
 		buf.Write(data)
 	}
 
-	response, nbTry, err := retry.ExecuteWithBackoffRetry(func() (interface{}, error) {
+	response, nbTry, err := retry.ExecuteWithBackoffRetryContext(ctx, func() (interface{}, error) {
 		return c.client.Bulk(bytes.NewReader(buf.Bytes()), c.client.Bulk.WithIndex(indexName), c.client.Bulk.WithContext(ctx))
 	}, backoffRetryCount, time.Duration(backoffTimeSeconds)*time.Second)
 	if err != nil {
 		log.Warn().Err(err).Msgf("error while bulk indexing in %s after %d tries", indexName, nbTry)
-		return fmt.Errorf("error while bulk indexing in %s", indexName)
+		return model.BulkResult{}, fmt.Errorf("error while bulk indexing in %s", indexName)
 	}
 
 	bulkResp := response.(*esapi.Response)
 	defer closeBodyResponse(bulkResp)
 
 	if bulkResp.IsError() {
-		return handleBulkResponseError(bulkResp)
+		return model.BulkResult{}, handleBulkResponseError(bulkResp)
 	}
 
 	return handleBulkResponse(bulkResp)
 }
 
+// bulkIndexMeta returns the action line of a bulk index request for the given item.
+// Items implementing model.Document are indexed under their own identifier, with their external version
+// if they implement model.Versioned and it is requested.
+func bulkIndexMeta(indexName string, item interface{}, external bool) []byte {
+	if document, ok := item.(model.Document); ok && document.DocumentID() != "" {
+		if versioned, ok := item.(model.Versioned); ok && external && versioned.DocumentVersion() > 0 {
+			return []byte(fmt.Sprintf(`{ "index" : { "_index" : "%s", "_id" : "%s", "version" : %d, "version_type" : "external" } }%s`,
+				indexName, document.DocumentID(), versioned.DocumentVersion(), "\n"))
+		}
+		return []byte(fmt.Sprintf(`{ "index" : { "_index" : "%s", "_id" : "%s" } }%s`, indexName, document.DocumentID(), "\n"))
+	}
+	return []byte(fmt.Sprintf(`{ "index" : { "_index" : "%s" } }%s`, indexName, "\n"))
+}
+
 // handleBulkResponseError processes bulk response errors.
 func handleBulkResponseError(bulkResp *esapi.Response) error {
 	var raw map[string]interface{}
@@ -226,17 +459,28 @@ func handleBulkResponseError(bulkResp *esapi.Response) error {
 	return fmt.Errorf("error: [%d] %s: %s", bulkResp.StatusCode, errorType, errorReason)
 }
 
-// handleBulkResponse processes successful bulk responses.
-func handleBulkResponse(bulkResp *esapi.Response) error {
+// versionConflict is the type of the error of a write whose external version is not greater than the indexed one.
+const versionConflict = "version_conflict_engine_exception"
+
+// handleBulkResponse processes successful bulk responses. Stale documents are skipped, not rejected.
+func handleBulkResponse(bulkResp *esapi.Response) (model.BulkResult, error) {
 	var blk BulkResponse
 	if err := json.NewDecoder(bulkResp.Body).Decode(&blk); err != nil {
-		return fmt.Errorf("failure to parse response body: %s", err)
+		return model.BulkResult{}, fmt.Errorf("failure to parse response body: %s", err)
 	}
 
-	var numErrors int
+	var result model.BulkResult
 	for _, item := range blk.Items {
-		if item.Index.Status > 201 {
-			numErrors++
+		if item.Index.Status <= 201 {
+			result.Indexed++
+		} else if item.Index.Status == http.StatusConflict && item.Index.Error.Type == versionConflict {
+			// The indexed document has a greater or equal external version
+			result.Stale++
+			result.NotWritten = append(result.NotWritten, item.Index.ID)
+			log.Debug().Str("id", item.Index.ID).Msg("Bulk index skipped stale document")
+		} else {
+			result.Failed++
+			result.NotWritten = append(result.NotWritten, item.Index.ID)
 			log.Error().Int("status", item.Index.Status).
 				Str("type", item.Index.Error.Type).
 				Str("reason", item.Index.Error.Reason).
@@ -246,10 +490,10 @@ func handleBulkResponse(bulkResp *esapi.Response) error {
 		}
 	}
 
-	if numErrors > 0 {
-		return fmt.Errorf("indexed documents with [%d] errors", numErrors)
+	if result.Failed > 0 {
+		return result, fmt.Errorf("indexed documents with [%d] errors", result.Failed)
 	}
-	return nil
+	return result, nil
 }
 
 // createClient creates an Elasticsearch adapter using the given configuration.
@@ -307,3 +551,17 @@ type BulkResponse struct {
 		} `json:"index"`
 	} `json:"items"`
 }
+
+// scrollPage represents a page of the documents returned by a scroll.
+type scrollPage struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []scrollHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// scrollHit represents a document returned by a scroll, with its source if requested.
+type scrollHit struct {
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+}
@@ -0,0 +1,83 @@
+package elasticsearchv7
+
+import (
+	"encoding/json"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_BulkIndexMeta_WhenDocumentIsVersioned(t *testing.T) {
+	var meta struct {
+		Index map[string]interface{} `json:"index"`
+	}
+	line := bulkIndexMeta("programs-1", &model.Program{ID: "p1", Version: 1700000000000000}, true)
+
+	assert.True(t, strings.HasSuffix(string(line), "\n"), "Should end the action line")
+	assert.NoError(t, json.Unmarshal(line, &meta))
+	assert.Equal(t, map[string]interface{}{
+		"_index":       "programs-1",
+		"_id":          "p1",
+		"version":      float64(1700000000000000),
+		"version_type": "external",
+	}, meta.Index)
+}
+
+func Test_BulkIndexMeta_WhenDocumentIsNotVersioned(t *testing.T) {
+	var meta struct {
+		Index map[string]interface{} `json:"index"`
+	}
+
+	assert.NoError(t, json.Unmarshal(bulkIndexMeta("programs-1", &model.Program{ID: "p1"}, true), &meta))
+	assert.Equal(t, map[string]interface{}{"_index": "programs-1", "_id": "p1"}, meta.Index, "Should not check a zero version")
+
+	var other struct {
+		Index map[string]interface{} `json:"index"`
+	}
+	assert.NoError(t, json.Unmarshal(bulkIndexMeta("medias-1", map[string]string{"name": "media"}, true), &other))
+	assert.Equal(t, map[string]interface{}{"_index": "medias-1"}, other.Index, "Should let the search engine identify other items")
+}
+
+func Test_BulkIndexMeta_WhenVersionIsNotChecked(t *testing.T) {
+	var meta struct {
+		Index map[string]interface{} `json:"index"`
+	}
+
+	assert.NoError(t, json.Unmarshal(bulkIndexMeta("programs-1", &model.Program{ID: "p1", Version: 1700000000000000}, false), &meta))
+	assert.Equal(t, map[string]interface{}{"_index": "programs-1", "_id": "p1"}, meta.Index, "Should leave the version to the search engine")
+}
+
+func Test_HandleBulkResponse_WhenDocumentIsStale(t *testing.T) {
+	response := &esapi.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{
+		"errors": true,
+		"items": [
+			{"index": {"_id": "p1", "result": "created", "status": 201}},
+			{"index": {"_id": "p2", "status": 409, "error": {"type": "version_conflict_engine_exception", "reason": "current version [3] is higher or equal to the one provided [2]"}}},
+			{"index": {"_id": "p3", "result": "updated", "status": 200}}
+		]
+	}`))}
+
+	result, err := handleBulkResponse(response)
+
+	assert.NoError(t, err, "Should not fail on a stale document")
+	assert.Equal(t, model.BulkResult{Indexed: 2, Stale: 1, NotWritten: []string{"p2"}}, result)
+}
+
+func Test_HandleBulkResponse_WhenDocumentIsRejected(t *testing.T) {
+	response := &esapi.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{
+		"errors": true,
+		"items": [
+			{"index": {"_id": "p1", "status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse field [Position]"}}},
+			{"index": {"_id": "p2", "status": 409, "error": {"type": "version_conflict_engine_exception", "reason": "stale"}}}
+		]
+	}`))}
+
+	result, err := handleBulkResponse(response)
+
+	assert.Error(t, err)
+	assert.Equal(t, model.BulkResult{Failed: 1, Stale: 1, NotWritten: []string{"p1", "p2"}}, result)
+}
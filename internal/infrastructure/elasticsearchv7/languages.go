@@ -0,0 +1,88 @@
+package elasticsearchv7
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+)
+
+// localizedFields are the fields holding text by language, see model.LocalizedText.
+var localizedFields = []string{"LocalizedName", "LocalizedDescription"}
+
+// languageAnalysis holds the analysis settings of a language: its analyzer, see analyzerName,
+// and the token filters the analyzer uses. Every analyzer folds accents, so that "emission" matches "émission".
+type languageAnalysis struct {
+	analyzer map[string]interface{}
+	filters  map[string]interface{}
+}
+
+// languageAnalyses are the analysis settings of the languages available for the localized fields.
+var languageAnalyses = map[string]languageAnalysis{
+	model.LanguageFrench: {
+		analyzer: map[string]interface{}{
+			"tokenizer": "standard",
+			"filter":    []string{"french_elision", "lowercase", "french_stop", "asciifolding", "french_stemmer"},
+		},
+		filters: map[string]interface{}{
+			"french_elision": map[string]interface{}{
+				"type":          "elision",
+				"articles_case": true,
+				"articles":      []string{"l", "m", "t", "qu", "n", "s", "j", "d", "c", "jusqu", "quoiqu", "lorsqu", "puisqu"},
+			},
+			"french_stop":    map[string]interface{}{"type": "stop", "stopwords": "_french_"},
+			"french_stemmer": map[string]interface{}{"type": "stemmer", "language": "light_french"},
+		},
+	},
+	model.LanguageEnglish: {
+		analyzer: map[string]interface{}{
+			"tokenizer": "standard",
+			"filter":    []string{"english_possessive_stemmer", "lowercase", "english_stop", "asciifolding", "english_stemmer"},
+		},
+		filters: map[string]interface{}{
+			"english_possessive_stemmer": map[string]interface{}{"type": "stemmer", "language": "possessive_english"},
+			"english_stop":               map[string]interface{}{"type": "stop", "stopwords": "_english_"},
+			"english_stemmer":            map[string]interface{}{"type": "stemmer", "language": "english"},
+		},
+	},
+}
+
+// withLanguages adds to an index definition the localized fields, with a sub-field per language analyzed
+// by its analyzers, along with the keyword field of the detected language. The analyzers are defined by withAnalysis.
+// The localized fields are objects next to Name and Description, not multi-fields of them, see model.LocalizedText.
+func withLanguages(definition string, languages []string) (string, error) {
+	var index map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(definition), &index); err != nil {
+		return "", err
+	}
+
+	languageFields := make(map[string]interface{}, len(languages))
+	for _, language := range languages {
+		if _, ok := languageAnalyses[language]; !ok {
+			return "", fmt.Errorf("no analyzer for language %q", language)
+		}
+		languageFields[language] = map[string]interface{}{
+			"type":            "text",
+			"analyzer":        analyzerName(language),
+			"search_analyzer": searchAnalyzerName(language),
+		}
+	}
+
+	properties := index["mappings"]["properties"].(map[string]interface{})
+	for _, field := range localizedFields {
+		properties[field] = map[string]interface{}{"properties": languageFields}
+	}
+	properties["Language"] = map[string]interface{}{"type": "keyword"}
+
+	data, err := json.Marshal(index)
+	return string(data), err
+}
+
+// analyzerName returns the name of the analyzer of a language, e.g. "fr_folded".
+func analyzerName(language string) string {
+	return language + "_folded"
+}
+
+// searchAnalyzerName returns the name of the search analyzer of a language, e.g. "fr_folded_search".
+func searchAnalyzerName(language string) string {
+	return analyzerName(language) + "_search"
+}
@@ -2,7 +2,9 @@ package elasticsearchv7
 
 import (
 	"errors"
+	"fmt"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 )
 
 const catsMetadataJSON = `{
@@ -21,11 +23,26 @@ const catsMetadataJSON = `{
           "type": "keyword"
         },
         "Name": {
-          "type": "text"
+          "type": "text",
+          "fields": {
+            "suggest": {
+              "type": "search_as_you_type"
+            }
+          }
         },
         "Description": {
           "type": "text"
         },
+        "Slug": {
+          "type": "keyword"
+        },
+        "Summary": {
+          "type": "text"
+        },
+        "RawDescription": {
+          "type": "text",
+          "index": false
+        },
         "ParentUUID": {
           "type": "keyword"
         }
@@ -49,10 +66,25 @@ const tagsMetadataJSON = `{
           "type": "keyword"
         },
         "Name": {
-          "type": "text"
+          "type": "text",
+          "fields": {
+            "suggest": {
+              "type": "search_as_you_type"
+            }
+          }
         },
         "Description": {
           "type": "text"
+        },
+        "Slug": {
+          "type": "keyword"
+        },
+        "Summary": {
+          "type": "text"
+        },
+        "RawDescription": {
+          "type": "text",
+          "index": false
         }
       }
   }
@@ -77,6 +109,16 @@ const wallsMetadataJSON = `{
         },
         "Description": {
           "type": "text"
+        },
+        "Slug": {
+          "type": "keyword"
+        },
+        "Summary": {
+          "type": "text"
+        },
+        "RawDescription": {
+          "type": "text",
+          "index": false
         }
       }
   }
@@ -101,6 +143,16 @@ const blocksMetadataJSON = `{
         },
         "Description": {
           "type": "text"
+        },
+        "Slug": {
+          "type": "keyword"
+        },
+        "Summary": {
+          "type": "text"
+        },
+        "RawDescription": {
+          "type": "text",
+          "index": false
         },
 		"Kind": {
           "type": "keyword"
@@ -124,10 +176,35 @@ const programsMetadataJSON = `{
           "type": "keyword"
         },
         "Name": {
-          "type": "text"
+          "type": "text",
+          "fields": {
+            "suggest": {
+              "type": "search_as_you_type"
+            },
+            "sort": {
+              "type": "keyword",
+              "ignore_above": 256
+            }
+          }
         },
         "Description": {
           "type": "text"
+        },
+        "Slug": {
+          "type": "keyword"
+        },
+        "Summary": {
+          "type": "text"
+        },
+        "RawDescription": {
+          "type": "text",
+          "index": false
+        },
+        "EpisodeCount": {
+          "type": "integer"
+        },
+        "WordCount": {
+          "type": "integer"
         }
       }
   }
@@ -148,17 +225,46 @@ const episodesMetadataJSON = `{
           "type": "keyword"
         },
         "Name": {
-          "type": "text"
+          "type": "text",
+          "fields": {
+            "suggest": {
+              "type": "search_as_you_type"
+            },
+            "sort": {
+              "type": "keyword",
+              "ignore_above": 256
+            }
+          }
         },
         "Description": {
           "type": "text"
+        },
+        "Slug": {
+          "type": "keyword"
+        },
+        "Summary": {
+          "type": "text"
+        },
+        "RawDescription": {
+          "type": "text",
+          "index": false
+        },
+        "WordCount": {
+          "type": "integer"
         },
 		"Position": {
           "type": "integer"
         },
 		"ProgramID": {
           "type": "keyword"
-		}
+		},
+        "Media": {
+          "properties": {
+            "Kind": {
+              "type": "keyword"
+            }
+          }
+        }
       }
   }
 }`
@@ -213,3 +319,22 @@ func metadataByDocsType(docsType string) (string, error) {
 	}
 
 }
+
+// indexDefinition returns the settings and mappings used to create an index of the given docs type,
+// with the localized fields of the languages of the docs type if it has any, and the analyzers applying the search terms.
+func indexDefinition(docsType string, languages []string, terms model.SearchTerms, synonymsPath string) (string, error) {
+	metadata, err := metadataByDocsType(docsType)
+	if err != nil {
+		return "", err
+	}
+	definition := fmt.Sprintf(metadata, 10, 1, 5, 200)
+	if docsType == api.Medias {
+		languages = nil
+	}
+	if len(languages) > 0 {
+		if definition, err = withLanguages(definition, languages); err != nil {
+			return "", err
+		}
+	}
+	return withAnalysis(definition, languages, terms, synonymsPath)
+}
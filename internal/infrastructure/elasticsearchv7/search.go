@@ -0,0 +1,310 @@
+package elasticsearchv7
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strings"
+)
+
+// suggestField is the search-as-you-type sub-field of the names, see the mappings.
+const suggestField = "Name.suggest"
+
+// textFields are the fields searched by the full-text queries of the docs types with a name and a description.
+var textFields = []string{"Name^3", "LocalizedName.*^3", "Summary", "Description", "LocalizedDescription.*"}
+
+// searchFields are the fields searched by the full-text queries of each docs type, with their boosts.
+var searchFields = map[string][]string{
+	api.Cats:     textFields,
+	api.Tags:     textFields,
+	api.Walls:    textFields,
+	api.Programs: textFields,
+	api.Episodes: textFields,
+	api.Medias:   {"DirectLink"},
+}
+
+// highlightFields are the fields whose matching fragments are highlighted for each docs type.
+var highlightFields = map[string][]string{
+	api.Programs: {"Name", "Description"},
+	api.Episodes: {"Name", "Description"},
+	api.Medias:   {"DirectLink"},
+}
+
+// kindFields are the fields holding the media kind of each docs type filtered by kind.
+var kindFields = map[string]string{
+	api.Episodes: "Media.Kind",
+	api.Medias:   "Kind",
+}
+
+// sortFields are the fields sorting the documents by each sort.
+var sortFields = map[string]string{
+	model.SortRelevance:    "_score",
+	model.SortName:         "Name.sort",
+	model.SortPosition:     "Position",
+	model.SortEpisodeCount: "EpisodeCount",
+}
+
+// searchAdapter represents an Elasticsearch adapter querying the latest indexes.
+type searchAdapter struct {
+	client *elasticsearch.Client
+}
+
+// NewSearchAdapter initializes a new Elasticsearch search adapter with the given configuration.
+func NewSearchAdapter(config *configuration.AppConfig) (port.Searcher, error) {
+	esClient, err := createClient(config)
+	if err != nil {
+		log.Error().Err(err).Msg("elasticsearch search adapter init failed")
+		return nil, fmt.Errorf("elasticsearch search adapter init failed: %w", err)
+	}
+	return &searchAdapter{client: esClient}, nil
+}
+
+// searchRequest is a search of the latest index of a docs type, part of a multi-search.
+type searchRequest struct {
+	docsType string
+	body     map[string]interface{}
+}
+
+// searchResponse is the response to a search of a multi-search.
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []searchHit `json:"hits"`
+	} `json:"hits"`
+	Shards struct {
+		Failed int `json:"failed"`
+	} `json:"_shards"`
+	Error  json.RawMessage `json:"error"`
+	Status int             `json:"status"`
+}
+
+// searchHit is a document matching a search.
+type searchHit struct {
+	ID        string              `json:"_id"`
+	Score     float64             `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight"`
+}
+
+// Suggest returns, per docs type, at most size documents whose name completes the query, best first.
+// The last word of the query is matched as a prefix, the previous ones as whole words.
+func (s *searchAdapter) Suggest(ctx context.Context, query string, docsTypes []string, size int) (map[string][]model.Suggestion, error) {
+	requests := make([]searchRequest, 0, len(docsTypes))
+	for _, docsType := range docsTypes {
+		requests = append(requests, searchRequest{docsType: docsType, body: map[string]interface{}{
+			"size":    size,
+			"_source": []string{"Name"},
+			"query": map[string]interface{}{
+				"multi_match": map[string]interface{}{
+					"query":  query,
+					"type":   "bool_prefix",
+					"fields": []string{suggestField, suggestField + "._2gram", suggestField + "._3gram"},
+				},
+			},
+		}})
+	}
+	responses, err := s.multiSearch(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make(map[string][]model.Suggestion, len(responses))
+	for i, response := range responses {
+		docsType := requests[i].docsType
+		for _, hit := range response.Hits.Hits {
+			var source struct {
+				Name string `json:"Name"`
+			}
+			if err := json.Unmarshal(hit.Source, &source); err != nil {
+				return nil, fmt.Errorf("could not decode %s %s: %w", docsType, hit.ID, err)
+			}
+			suggestions[docsType] = append(suggestions[docsType], model.Suggestion{ID: hit.ID, Text: source.Name, Score: hit.Score})
+		}
+	}
+	return suggestions, nil
+}
+
+// Search returns the page of the documents of the docs type matching the query, in its latest index.
+// The documents with the same sort value are ordered by identifier, so that the pages do not overlap.
+func (s *searchAdapter) Search(ctx context.Context, docsType string, query model.SearchQuery) (model.SearchResult, error) {
+	var must interface{} = map[string]interface{}{"match_all": map[string]interface{}{}}
+	if query.Text != "" {
+		must = textQuery(docsType, query.Text)
+	}
+	filters := []interface{}{}
+	if query.ProgramID != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"ProgramID": query.ProgramID}})
+	}
+	if query.Kind != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{kindFields[docsType]: query.Kind}})
+	}
+
+	body := map[string]interface{}{
+		"from":             query.Offset,
+		"size":             query.Limit,
+		"track_total_hits": true,
+		"query":            map[string]interface{}{"bool": map[string]interface{}{"must": must, "filter": filters}},
+		"sort": []interface{}{
+			map[string]interface{}{sortFields[query.Sort]: query.Order},
+			map[string]interface{}{"ID": model.OrderAsc},
+		},
+	}
+	if query.Highlight && query.Text != "" {
+		fields := make(map[string]interface{}, len(highlightFields[docsType]))
+		for _, field := range highlightFields[docsType] {
+			fields[field] = map[string]interface{}{}
+		}
+		body["highlight"] = map[string]interface{}{"fields": fields}
+	}
+
+	responses, err := s.multiSearch(ctx, []searchRequest{{docsType: docsType, body: body}})
+	if err != nil {
+		return model.SearchResult{}, err
+	}
+	result := model.SearchResult{Total: responses[0].Hits.Total.Value}
+	for _, hit := range responses[0].Hits.Hits {
+		result.Hits = append(result.Hits, model.SearchHit{ID: hit.ID, Score: hit.Score, Document: hit.Source, Highlights: hit.Highlight})
+	}
+	return result, nil
+}
+
+// SearchAll returns, per docs type, at most size documents matching the text, best first, searched at once.
+func (s *searchAdapter) SearchAll(ctx context.Context, text string, docsTypes []string, size int) (map[string]model.SearchResult, error) {
+	requests := make([]searchRequest, 0, len(docsTypes))
+	for _, docsType := range docsTypes {
+		requests = append(requests, searchRequest{docsType: docsType, body: map[string]interface{}{
+			"size":             size,
+			"track_total_hits": true,
+			"query":            textQuery(docsType, text),
+		}})
+	}
+	responses, err := s.multiSearch(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]model.SearchResult, len(responses))
+	for i, response := range responses {
+		result := model.SearchResult{Total: response.Hits.Total.Value}
+		for _, hit := range response.Hits.Hits {
+			result.Hits = append(result.Hits, model.SearchHit{ID: hit.ID, Score: hit.Score, Document: hit.Source})
+		}
+		results[requests[i].docsType] = result
+	}
+	return results, nil
+}
+
+// textQuery returns the full-text query of the text in the fields of the docs type.
+func textQuery(docsType, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"multi_match": map[string]interface{}{
+			"query":  text,
+			"fields": searchFields[docsType],
+		},
+	}
+}
+
+// multiSearch runs the searches in a single request and returns their responses in the same order.
+// Each search targets the latest index of its docs type; a docs type without latest index has no hits.
+// A search failing on some shards fails, rather than returning partial results.
+func (s *searchAdapter) multiSearch(ctx context.Context, requests []searchRequest) ([]searchResponse, error) {
+	indexes, err := s.latestIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	var searched []int
+	for i, request := range requests {
+		indexName, ok := indexes[request.docsType]
+		if !ok {
+			continue
+		}
+		if err := encoder.Encode(map[string]interface{}{"index": indexName}); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(request.body); err != nil {
+			return nil, err
+		}
+		searched = append(searched, i)
+	}
+	responses := make([]searchResponse, len(requests))
+	if len(searched) == 0 {
+		return responses, nil
+	}
+
+	response, err := s.client.Msearch(&body, s.client.Msearch.WithContext(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("multi-search failed")
+		return nil, err
+	}
+	defer closeBodyResponse(response)
+	if response.IsError() {
+		log.Error().Interface("response-code", response.StatusCode).Msg("multi-search failed : response code")
+		return nil, fmt.Errorf("error while searching: %s", response.Status())
+	}
+
+	var result struct {
+		Responses []searchResponse `json:"responses"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failure to parse response body: %s", err)
+	}
+	if len(result.Responses) != len(searched) {
+		return nil, fmt.Errorf("expected %d search responses, got %d", len(searched), len(result.Responses))
+	}
+	for i, response := range result.Responses {
+		docsType := requests[searched[i]].docsType
+		if len(response.Error) > 0 {
+			return nil, fmt.Errorf("error while searching %s: %s", docsType, response.Error)
+		}
+		if response.Shards.Failed > 0 {
+			log.Error().Int("failed", response.Shards.Failed).Str("docs-type", docsType).Msg("search failed on shards")
+			return nil, fmt.Errorf("error while searching %s: %d shards failed", docsType, response.Shards.Failed)
+		}
+		responses[searched[i]] = response
+	}
+	return responses, nil
+}
+
+// latestIndexes returns the index of each docs type held by the latest alias, the last one by name if several.
+func (s *searchAdapter) latestIndexes(ctx context.Context) (map[string]string, error) {
+	response, err := s.client.Indices.GetAlias(s.client.Indices.GetAlias.WithName(latestAlias), s.client.Indices.GetAlias.WithContext(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("getting latest indexes failed")
+		return nil, err
+	}
+	defer closeBodyResponse(response)
+	indexes := make(map[string]string)
+	if response.StatusCode == http.StatusNotFound {
+		return indexes, nil
+	}
+	if response.IsError() {
+		log.Error().Interface("response-code", response.StatusCode).Msg("getting latest indexes failed : response code")
+		return nil, fmt.Errorf("error while getting latest indexes: %s", response.Status())
+	}
+
+	var aliases map[string]interface{}
+	if err := json.NewDecoder(response.Body).Decode(&aliases); err != nil {
+		return nil, fmt.Errorf("failure to parse response body: %s", err)
+	}
+	for indexName := range aliases {
+		for _, docsType := range api.DocsTypes {
+			if strings.HasPrefix(indexName, docsType+"-") && indexName > indexes[docsType] {
+				indexes[docsType] = indexName
+			}
+		}
+	}
+	return indexes, nil
+}
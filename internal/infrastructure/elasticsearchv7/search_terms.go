@@ -0,0 +1,180 @@
+package elasticsearchv7
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the analyzers of the text fields without explicit analyzer, and of the filters applying the search terms.
+// The stopwords are removed both when indexing and when searching, the synonyms are only expanded when searching,
+// so that the synonyms filter, and the search analyzers using it, can be reloaded on a live index, see ReloadSearchTerms.
+const (
+	defaultAnalyzer       = "default"
+	defaultSearchAnalyzer = "default_search"
+	indexStopFilter       = "index_stop"
+	searchStopFilter      = "search_stop"
+	searchSynonymsFilter  = "search_synonyms"
+)
+
+// withAnalysis adds to an index definition the analysis settings: the default analyzers and the analyzers
+// of the languages, all applying the search terms.
+// The synonyms are read from the synonyms path, relative to the configuration directory of Elasticsearch, if set.
+func withAnalysis(definition string, languages []string, terms model.SearchTerms, synonymsPath string) (string, error) {
+	var index map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(definition), &index); err != nil {
+		return "", err
+	}
+
+	analyzers, filters := searchAnalysis(languages, terms, synonymsPath)
+	analyzers[defaultAnalyzer] = analyzer("standard", []string{"lowercase"}, indexStopFilter)
+	filters[indexStopFilter] = stopFilter(terms.Stopwords)
+	for _, language := range languages {
+		analysis := languageAnalyses[language]
+		analyzers[analyzerName(language)] = languageAnalyzer(analysis, indexStopFilter)
+		for name, filter := range analysis.filters {
+			filters[name] = filter
+		}
+	}
+	index["settings"]["analysis"] = map[string]interface{}{"analyzer": analyzers, "filter": filters}
+
+	data, err := json.Marshal(index)
+	return string(data), err
+}
+
+// searchAnalysis returns the search analyzers, the default one and those of the languages, along with the filters
+// applying the search terms they use. The filters of the languages are left out, they never change.
+// With a synonyms path, the synonyms filter reads the synonyms file and is updateable: it can be reloaded without
+// closing the index. Otherwise the synonyms are inline and only change with the indexes created from now on.
+func searchAnalysis(languages []string, terms model.SearchTerms, synonymsPath string) (map[string]interface{}, map[string]interface{}) {
+	termsFilters := []string{searchStopFilter}
+	filters := map[string]interface{}{searchStopFilter: stopFilter(terms.Stopwords)}
+	// An inline synonym filter without rules is rejected, it is only part of the analyzers when there are synonyms
+	if synonymsPath != "" {
+		termsFilters = append(termsFilters, searchSynonymsFilter)
+		filters[searchSynonymsFilter] = map[string]interface{}{
+			"type":          "synonym_graph",
+			"synonyms_path": synonymsPath,
+			"updateable":    true,
+			"lenient":       true,
+		}
+	} else if len(terms.Synonyms) > 0 {
+		termsFilters = append(termsFilters, searchSynonymsFilter)
+		filters[searchSynonymsFilter] = map[string]interface{}{
+			"type":     "synonym_graph",
+			"synonyms": terms.Synonyms,
+			"lenient":  true,
+		}
+	}
+
+	analyzers := map[string]interface{}{
+		defaultSearchAnalyzer: analyzer("standard", []string{"lowercase"}, termsFilters...),
+	}
+	for _, language := range languages {
+		analyzers[searchAnalyzerName(language)] = languageAnalyzer(languageAnalyses[language], termsFilters...)
+	}
+	return analyzers, filters
+}
+
+// languageAnalyzer returns the analyzer of a language with the given filters inserted right after lowercasing.
+func languageAnalyzer(analysis languageAnalysis, termsFilters ...string) map[string]interface{} {
+	var filters []string
+	for _, filter := range analysis.analyzer["filter"].([]string) {
+		filters = append(filters, filter)
+		if filter == "lowercase" {
+			filters = append(filters, termsFilters...)
+		}
+	}
+	return analyzer(analysis.analyzer["tokenizer"].(string), filters)
+}
+
+// analyzer returns a custom analyzer made of the tokenizer and filters.
+func analyzer(tokenizer string, filters []string, termsFilters ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"tokenizer": tokenizer,
+		"filter":    append(append([]string{}, filters...), termsFilters...),
+	}
+}
+
+// stopFilter returns the filter removing the stopwords, removing nothing without stopwords.
+func stopFilter(stopwords []string) map[string]interface{} {
+	if len(stopwords) == 0 {
+		return map[string]interface{}{"type": "stop", "stopwords": "_none_"}
+	}
+	return map[string]interface{}{"type": "stop", "stopwords": stopwords, "ignore_case": true}
+}
+
+// ReloadSearchTerms updates the synonyms of the search analyzers of a live index, without reindexing nor closing it:
+// the synonyms file is rewritten and the search analyzers reloaded from it. The stopwords are part of the index
+// settings, they only change with the indexes created from now on.
+func (c *adapter) ReloadSearchTerms(ctx context.Context, indexName string, docsType string, terms model.SearchTerms) error {
+	if c.synonymsFile == "" {
+		return errors.New("synonyms file is not configured, the search analyzers cannot be reloaded")
+	}
+	if err := c.writeSynonyms(terms.Synonyms); err != nil {
+		return err
+	}
+
+	response, err := c.client.Indices.ReloadSearchAnalyzers([]string{indexName},
+		c.client.Indices.ReloadSearchAnalyzers.WithContext(ctx))
+	if err != nil {
+		log.Error().Err(err).Str("index", indexName).Msg("reloading search analyzers failed")
+		return err
+	}
+	defer closeBodyResponse(response)
+	if response.StatusCode != http.StatusOK {
+		log.Error().Interface("response-code", response.StatusCode).Str("index", indexName).Msg("reloading search analyzers failed : response code")
+		return fmt.Errorf("error while reloading search analyzers of index %s", indexName)
+	}
+
+	var result struct {
+		Shards struct {
+			Failed int `json:"failed"`
+		} `json:"_shards"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return fmt.Errorf("could not decode reload of index %s: %w", indexName, err)
+	}
+	if result.Shards.Failed > 0 {
+		log.Error().Int("failed", result.Shards.Failed).Str("index", indexName).Msg("reloading search analyzers failed on shards")
+		return fmt.Errorf("search analyzers of index %s not reloaded on %d shards", indexName, result.Shards.Failed)
+	}
+	return nil
+}
+
+// writeSynonyms replaces the synonyms file read by the search analyzers, one rule per line. The file is written
+// next to the current one and renamed over it, so that Elasticsearch never reads a partial file.
+func (c *adapter) writeSynonyms(synonyms []string) error {
+	var content strings.Builder
+	for _, rule := range synonyms {
+		content.WriteString(rule)
+		content.WriteString("\n")
+	}
+	temporary, err := os.CreateTemp(filepath.Dir(c.synonymsFile), filepath.Base(c.synonymsFile)+".*")
+	if err != nil {
+		return fmt.Errorf("could not write synonyms file: %w", err)
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.WriteString(content.String()); err != nil {
+		temporary.Close()
+		return fmt.Errorf("could not write synonyms file: %w", err)
+	}
+	if err := temporary.Close(); err != nil {
+		return fmt.Errorf("could not write synonyms file: %w", err)
+	}
+	// The temporary file is private, the file must be readable by Elasticsearch
+	if err := os.Chmod(temporary.Name(), 0644); err != nil {
+		return fmt.Errorf("could not write synonyms file: %w", err)
+	}
+	if err := os.Rename(temporary.Name(), c.synonymsFile); err != nil {
+		return fmt.Errorf("could not write synonyms file: %w", err)
+	}
+	return nil
+}
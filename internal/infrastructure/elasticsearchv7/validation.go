@@ -0,0 +1,102 @@
+package elasticsearchv7
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"math"
+	"sort"
+)
+
+// indexMappings mirrors the part of an index definition needed to validate documents.
+type indexMappings struct {
+	Mappings struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	} `json:"mappings"`
+}
+
+// ValidateDocuments checks the given items against the mapping of the docs type without sending them to Elasticsearch.
+// It reports documents without identifier, values whose JSON type does not match the mapped field type,
+// and mapped fields that none of the documents populate.
+func (c *adapter) ValidateDocuments(ctx context.Context, docsType string, items []interface{}) ([]model.DocumentIssue, error) {
+	definition, err := indexDefinition(docsType, c.languages[docsType], model.SearchTerms{}, "")
+	if err != nil {
+		return nil, err
+	}
+	var mappings indexMappings
+	if err := json.Unmarshal([]byte(definition), &mappings); err != nil {
+		return nil, fmt.Errorf("could not parse %s mapping: %w", docsType, err)
+	}
+	properties := mappings.Mappings.Properties
+
+	var issues []model.DocumentIssue
+	populated := make(map[string]bool, len(properties))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while encoding: %w", err)
+		}
+		var document map[string]interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("an error occurred while decoding: %w", err)
+		}
+
+		id, _ := document["ID"].(string)
+		if id == "" {
+			issues = append(issues, model.DocumentIssue{Field: "ID", Reason: "document has no identifier"})
+		}
+
+		for field, property := range properties {
+			value, ok := document[field]
+			if !ok || value == nil {
+				continue
+			}
+			populated[field] = true
+			if !matchesFieldType(property.Type, value) {
+				issues = append(issues, model.DocumentIssue{
+					DocumentID: id,
+					Field:      field,
+					Reason:     fmt.Sprintf("value %v does not match mapped type %s", value, property.Type),
+				})
+			}
+		}
+	}
+
+	if len(items) > 0 {
+		fields := make([]string, 0, len(properties))
+		for field := range properties {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if !populated[field] {
+				issues = append(issues, model.DocumentIssue{Field: field, Reason: "mapped field is never populated"})
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// matchesFieldType reports whether a decoded JSON value can be indexed in a field of the given mapping type.
+func matchesFieldType(fieldType string, value interface{}) bool {
+	switch fieldType {
+	case "keyword", "text", "date":
+		_, ok := value.(string)
+		return ok
+	case "integer", "long", "short", "byte":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "float", "double":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default:
+		return true
+	}
+}
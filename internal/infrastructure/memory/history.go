@@ -0,0 +1,62 @@
+// Package memory provides in-memory implementations of the persistence interfaces, meant for tests.
+package memory
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"sort"
+	"sync"
+)
+
+// historyAdapter is an in-memory implementation of the indexation history.
+type historyAdapter struct {
+	mu   sync.RWMutex
+	jobs map[string]model.Job
+}
+
+// NewHistoryAdapter creates a new empty in-memory history.
+func NewHistoryAdapter() port.History {
+	return &historyAdapter{
+		jobs: make(map[string]model.Job),
+	}
+}
+
+// Save inserts the job, or replaces its previous record.
+func (adapter *historyAdapter) Save(ctx context.Context, job model.Job) error {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	adapter.jobs[job.ID] = job
+	return nil
+}
+
+// Find returns the page of jobs selected by the filter, newest first.
+func (adapter *historyAdapter) Find(ctx context.Context, filter model.JobFilter) (model.JobPage, error) {
+	adapter.mu.RLock()
+	defer adapter.mu.RUnlock()
+
+	matching := make([]model.Job, 0, len(adapter.jobs))
+	for _, job := range adapter.jobs {
+		if filter.Matches(job) {
+			matching = append(matching, job)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].StartedAt.Equal(matching[j].StartedAt) {
+			return matching[i].ID < matching[j].ID
+		}
+		return matching[i].StartedAt.After(matching[j].StartedAt)
+	})
+
+	start := min(filter.Offset, len(matching))
+	end := len(matching)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(matching))
+	}
+	return model.JobPage{
+		Jobs:   matching[start:end],
+		Total:  len(matching),
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_HistoryFind_WhenPaginated(t *testing.T) {
+	history := NewHistoryAdapter()
+	startedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, docsType := range []string{"programs", "episodes", "programs", "programs"} {
+		job := model.Job{ID: string(rune('a' + i)), DocsType: docsType, StartedAt: startedAt.Add(time.Duration(i) * time.Hour)}
+		assert.NoError(t, history.Save(context.Background(), job))
+	}
+
+	page, err := history.Find(context.Background(), model.JobFilter{DocsType: "programs", Limit: 2, Offset: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	assert.Len(t, page.Jobs, 2)
+	assert.Equal(t, "c", page.Jobs[0].ID, "Should skip the newest job")
+	assert.Equal(t, "a", page.Jobs[1].ID)
+}
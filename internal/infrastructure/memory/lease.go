@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"sync"
+	"time"
+)
+
+// lease is a lease held until its expiry.
+type lease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// leaseAdapter is an in-memory implementation of the leases, shared by the holders of a single process.
+type leaseAdapter struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+// NewLeaseAdapter creates a new in-memory lease store without any lease.
+func NewLeaseAdapter() port.Lease {
+	return &leaseAdapter{
+		leases: make(map[string]lease),
+	}
+}
+
+// Acquire takes the lease if it is free or expired, or renews it if the holder already owns it.
+func (adapter *leaseAdapter) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+
+	now := time.Now()
+	current, ok := adapter.leases[name]
+	if ok && current.holder != holder && now.Before(current.expiresAt) {
+		return false, nil
+	}
+	adapter.leases[name] = lease{holder: holder, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Release gives up the lease if it is owned by the holder.
+func (adapter *leaseAdapter) Release(ctx context.Context, name, holder string) error {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+
+	if current, ok := adapter.leases[name]; ok && current.holder == holder {
+		delete(adapter.leases, name)
+	}
+	return nil
+}
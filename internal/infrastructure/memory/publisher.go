@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"sync"
+)
+
+// Publisher is an in-memory implementation of the index events publisher, recording the published events.
+type Publisher struct {
+	mu     sync.RWMutex
+	events []model.IndexEvent
+}
+
+// NewPublisher creates a new in-memory publisher without events.
+func NewPublisher() *Publisher {
+	return &Publisher{}
+}
+
+// Publish records the event.
+func (publisher *Publisher) Publish(ctx context.Context, event model.IndexEvent) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns the published events, oldest first.
+func (publisher *Publisher) Events() []model.IndexEvent {
+	publisher.mu.RLock()
+	defer publisher.mu.RUnlock()
+	return append([]model.IndexEvent(nil), publisher.events...)
+}
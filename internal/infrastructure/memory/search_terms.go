@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"sync"
+)
+
+// searchTermsAdapter is an in-memory implementation of the search terms store.
+type searchTermsAdapter struct {
+	mu    sync.RWMutex
+	terms model.SearchTerms
+}
+
+// NewSearchTermsAdapter creates a new in-memory search terms store without synonyms nor stopwords.
+func NewSearchTermsAdapter() port.SearchTerms {
+	return &searchTermsAdapter{
+		terms: model.SearchTerms{Synonyms: []string{}, Stopwords: []string{}},
+	}
+}
+
+// Find returns the saved search terms.
+func (adapter *searchTermsAdapter) Find(ctx context.Context) (model.SearchTerms, error) {
+	adapter.mu.RLock()
+	defer adapter.mu.RUnlock()
+	return adapter.terms, nil
+}
+
+// Save replaces the search terms.
+func (adapter *searchTermsAdapter) Save(ctx context.Context, terms model.SearchTerms) error {
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	adapter.terms = terms
+	return nil
+}
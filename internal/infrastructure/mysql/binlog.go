@@ -0,0 +1,368 @@
+// Package mysql provides MySQL implementations of the persistence interfaces.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"github.com/rs/zerolog/log"
+	stdlog "log"
+	"net"
+	"reflect"
+	"strconv"
+)
+
+// errMissingRowMetadata is returned when the row events do not carry the column names.
+var errMissingRowMetadata = errors.New("binlog row events carry no column names, binlog_row_metadata must be FULL")
+
+// errServerIDInUse is returned when the server identifier of the replication is already used by the server or a replica.
+var errServerIDInUse = errors.New("binlog server ID already in use, MYSQL_BINLOG_SERVER_ID must be unique")
+
+// binlogTable maps the rows of a table to the documents of a docs type.
+type binlogTable struct {
+	docsType string
+	document func(row map[string]interface{}) (interface{}, error)
+}
+
+// binlogTables are the tables captured from the binlog, by name.
+var binlogTables = map[string]binlogTable{
+	"category": {docsType: api.Cats, document: func(row map[string]interface{}) (interface{}, error) {
+		var categoryDB CategoryDB
+		if err := scanRow(row, &categoryDB); err != nil {
+			return nil, err
+		}
+		category := categoryDB.ToDomainModel()
+		return &category, nil
+	}},
+	"tag": {docsType: api.Tags, document: func(row map[string]interface{}) (interface{}, error) {
+		var tagDB TagDB
+		if err := scanRow(row, &tagDB); err != nil {
+			return nil, err
+		}
+		tag := tagDB.ToDomainModel()
+		return &tag, nil
+	}},
+	"wall": {docsType: api.Walls, document: func(row map[string]interface{}) (interface{}, error) {
+		var wallDB WallDB
+		if err := scanRow(row, &wallDB); err != nil {
+			return nil, err
+		}
+		wall := wallDB.ToDomainModel()
+		return &wall, nil
+	}},
+	"block": {docsType: api.Blocks, document: func(row map[string]interface{}) (interface{}, error) {
+		var blockDB BlockDB
+		if err := scanRow(row, &blockDB); err != nil {
+			return nil, err
+		}
+		block := blockDB.ToDomainModel()
+		return &block, nil
+	}},
+	"program": {docsType: api.Programs, document: func(row map[string]interface{}) (interface{}, error) {
+		var programDB ProgramDB
+		if err := scanRow(row, &programDB); err != nil {
+			return nil, err
+		}
+		program := programDB.ToDomainModel()
+		return &program, nil
+	}},
+	"episode": {docsType: api.Episodes, document: func(row map[string]interface{}) (interface{}, error) {
+		var episodeDB EpisodeDB
+		if err := scanRow(row, &episodeDB); err != nil {
+			return nil, err
+		}
+		episode := episodeDB.ToDomainModel()
+		return &episode, nil
+	}},
+	"media": {docsType: api.Medias, document: func(row map[string]interface{}) (interface{}, error) {
+		var mediaDB MediaDB
+		if err := scanRow(row, &mediaDB); err != nil {
+			return nil, err
+		}
+		media := mediaDB.ToDomainModel()
+		return &media, nil
+	}},
+}
+
+// binlogAdapter is a struct that acts as an adapter for the changes captured from the binlog of the MySQL database.
+type binlogAdapter struct {
+	client   *client
+	config   configuration.BinlogConfig
+	syncer   replication.BinlogSyncerConfig
+	database string
+}
+
+// NewBinlogAdapter creates a new binlog adapter with the provided MySQL client, replicating from the server
+// and with the credentials of the configured DSN. It fails unless the checkpoint table has the columns of the migrations.
+func NewBinlogAdapter(client *client, config *configuration.AppConfig) port.ChangeStream {
+	dsn, err := mysqldriver.ParseDSN(config.DatabaseConfig.DSN)
+	if err != nil {
+		stdlog.Fatalf("could not parse mysql dsn: %s", err.Error())
+	}
+	host, port, err := net.SplitHostPort(dsn.Addr)
+	if err != nil {
+		stdlog.Fatalf("could not parse mysql address: %s", err.Error())
+	}
+	portNumber, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		stdlog.Fatalf("could not parse mysql port: %s", err.Error())
+	}
+	if err := checkSchema(client.db, "indexer_binlog_checkpoint", "name", "file", "position", "updatedAt"); err != nil {
+		stdlog.Fatalf("could not use binlog checkpoint table: %s", err.Error())
+	}
+	return &binlogAdapter{
+		client: client,
+		config: config.Binlog,
+		syncer: replication.BinlogSyncerConfig{
+			ServerID:  config.Binlog.ServerID,
+			Flavor:    gomysql.MySQLFlavor,
+			Host:      host,
+			Port:      uint16(portNumber),
+			User:      dsn.User,
+			Password:  dsn.Passwd,
+			ParseTime: true,
+		},
+		database: dsn.DBName,
+	}
+}
+
+// Stream replicates the binlog from the checkpoint, or from the current position of the server when there is none,
+// and passes the changes of the captured tables to the handler. Inserted and updated rows are upserts carrying the
+// document mapped from the row, deleted and soft-deleted rows are deletes.
+// A server identifier already used by the server or by a connected replica is rejected, since the server would
+// disconnect the other replica.
+func (adapter *binlogAdapter) Stream(ctx context.Context, handler port.ChangeHandler) error {
+	if err := adapter.checkServerID(ctx); err != nil {
+		return err
+	}
+	position, err := adapter.position(ctx)
+	if err != nil {
+		return fmt.Errorf("could not read binlog position: %w", err)
+	}
+
+	syncer := replication.NewBinlogSyncer(adapter.syncer)
+	defer syncer.Close()
+	streamer, err := syncer.StartSync(position)
+	if err != nil {
+		return fmt.Errorf("could not start binlog replication: %w", err)
+	}
+	log.Ctx(ctx).Info().Str("file", position.Name).Uint32("position", position.Pos).Msg("binlog replication started")
+
+	for {
+		event, err := streamer.GetEvent(ctx)
+		if err != nil {
+			return err
+		}
+		switch e := event.Event.(type) {
+		case *replication.RotateEvent:
+			position = gomysql.Position{Name: string(e.NextLogName), Pos: uint32(e.Position)}
+		case *replication.RowsEvent:
+			changes, err := adapter.changes(event.Header.EventType, e)
+			if err != nil {
+				return err
+			}
+			for _, change := range changes {
+				if err := handler(ctx, change); err != nil {
+					return err
+				}
+			}
+		case *replication.XIDEvent:
+			// The transaction is committed and its changes are handled
+			position.Pos = event.Header.LogPos
+			if err := adapter.checkpoint(ctx, position); err != nil {
+				return fmt.Errorf("could not checkpoint binlog position: %w", err)
+			}
+		}
+	}
+}
+
+// changes maps the rows of a rows event to changes, ignoring the tables that are not captured.
+// Updates carry the rows before and after the update in turn: only the rows after it are mapped.
+func (adapter *binlogAdapter) changes(eventType replication.EventType, e *replication.RowsEvent) ([]model.Change, error) {
+	if string(e.Table.Schema) != adapter.database {
+		return nil, nil
+	}
+	table, ok := binlogTables[string(e.Table.Table)]
+	if !ok {
+		return nil, nil
+	}
+
+	operation, rows := model.ChangeUpsert, e.Rows
+	switch eventType {
+	case replication.WRITE_ROWS_EVENTv1, replication.WRITE_ROWS_EVENTv2:
+	case replication.UPDATE_ROWS_EVENTv1, replication.UPDATE_ROWS_EVENTv2:
+		rows = nil
+		for i := 1; i < len(e.Rows); i += 2 {
+			rows = append(rows, e.Rows[i])
+		}
+	case replication.DELETE_ROWS_EVENTv1, replication.DELETE_ROWS_EVENTv2:
+		operation = model.ChangeDelete
+	default:
+		return nil, nil
+	}
+
+	columns := e.Table.ColumnNameString()
+	if len(columns) == 0 {
+		return nil, errMissingRowMetadata
+	}
+	changes := make([]model.Change, 0, len(rows))
+	for _, values := range rows {
+		row := make(map[string]interface{}, len(columns))
+		for i, value := range values {
+			if i < len(columns) {
+				row[columns[i]] = value
+			}
+		}
+
+		var key struct {
+			UUID uuid.UUID `db:"UUID"`
+		}
+		if err := scanRow(row, &key); err != nil {
+			return nil, fmt.Errorf("could not map %s row: %w", e.Table.Table, err)
+		}
+		change := model.Change{Operation: operation, DocsType: table.docsType, ID: key.UUID.String()}
+		if row["deletedAt"] != nil {
+			// Soft-deleted rows are removed like deleted ones
+			change.Operation = model.ChangeDelete
+		}
+		if change.Operation == model.ChangeUpsert {
+			document, err := table.document(row)
+			if err != nil {
+				return nil, fmt.Errorf("could not map %s row: %w", e.Table.Table, err)
+			}
+			change.Document = document
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// checkServerID fails with errServerIDInUse if the server identifier of the replication is the one of the server
+// or of a replica connected to it.
+func (adapter *binlogAdapter) checkServerID(ctx context.Context) error {
+	var serverID uint32
+	if err := adapter.client.db.GetContext(ctx, &serverID, "SELECT @@server_id;"); err != nil {
+		return fmt.Errorf("could not read server ID: %w", err)
+	}
+	if serverID == adapter.syncer.ServerID {
+		return fmt.Errorf("%w: %d is the server ID of the MySQL server", errServerIDInUse, serverID)
+	}
+
+	rows, err := adapter.client.db.QueryContext(ctx, "SHOW REPLICAS;")
+	if err != nil {
+		// Before MySQL 8.0.22
+		if rows, err = adapter.client.db.QueryContext(ctx, "SHOW SLAVE HOSTS;"); err != nil {
+			return fmt.Errorf("could not list replicas: %w", err)
+		}
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		// The server identifier is the first column, the other columns are not needed
+		var replicaID uint32
+		values := make([]interface{}, len(columns))
+		values[0] = &replicaID
+		for i := 1; i < len(values); i++ {
+			values[i] = new(sql.RawBytes)
+		}
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		if replicaID == adapter.syncer.ServerID {
+			return fmt.Errorf("%w: %d is the server ID of a connected replica", errServerIDInUse, replicaID)
+		}
+	}
+	return rows.Err()
+}
+
+// position returns the checkpointed binlog position, or the current position of the server when there is none.
+func (adapter *binlogAdapter) position(ctx context.Context) (gomysql.Position, error) {
+	const query = `
+        SELECT file, position FROM indexer_binlog_checkpoint WHERE name = ?;
+    `
+	var checkpoint struct {
+		File     string `db:"file"`
+		Position uint32 `db:"position"`
+	}
+	err := adapter.client.db.GetContext(ctx, &checkpoint, query, adapter.config.Checkpoint)
+	if err == nil {
+		return gomysql.Position{Name: checkpoint.File, Pos: checkpoint.Position}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return gomysql.Position{}, err
+	}
+
+	// Other columns, such as the executed GTID set, are not needed
+	var status struct {
+		File     string `db:"File"`
+		Position uint32 `db:"Position"`
+	}
+	db := adapter.client.db.Unsafe()
+	if err := db.GetContext(ctx, &status, "SHOW BINARY LOG STATUS;"); err != nil {
+		// Before MySQL 8.2
+		if err := db.GetContext(ctx, &status, "SHOW MASTER STATUS;"); err != nil {
+			return gomysql.Position{}, err
+		}
+	}
+	return gomysql.Position{Name: status.File, Pos: status.Position}, nil
+}
+
+// checkpoint records the binlog position reached.
+func (adapter *binlogAdapter) checkpoint(ctx context.Context, position gomysql.Position) error {
+	const upsert = `
+        INSERT INTO indexer_binlog_checkpoint (name, file, position, updatedAt)
+        VALUES (?, ?, ?, NOW(3))
+        ON DUPLICATE KEY UPDATE file = VALUES(file), position = VALUES(position), updatedAt = VALUES(updatedAt);
+    `
+	_, err := adapter.client.db.ExecContext(ctx, upsert, adapter.config.Checkpoint, position.Name, position.Pos)
+	return err
+}
+
+// scanRow sets the fields of the database model pointed to by dest from the columns of a binlog row,
+// matched by their db tag. Columns missing from the row leave their field unset.
+func scanRow(row map[string]interface{}, dest interface{}) error {
+	value := reflect.ValueOf(dest).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		column := value.Type().Field(i).Tag.Get("db")
+		columnValue, ok := row[column]
+		if column == "" || !ok {
+			continue
+		}
+		if err := scanColumn(value.Field(i), columnValue); err != nil {
+			return fmt.Errorf("could not map column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// scanColumn sets a field from the value of a binlog column, with its Scan method if it has one.
+func scanColumn(field reflect.Value, columnValue interface{}) error {
+	// Binary columns, such as the UUIDs, are decoded as strings
+	if s, ok := columnValue.(string); ok {
+		columnValue = []byte(s)
+	}
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(columnValue)
+	}
+	if columnValue == nil {
+		return nil
+	}
+	converted := reflect.ValueOf(columnValue)
+	if !converted.Type().ConvertibleTo(field.Type()) {
+		return fmt.Errorf("unsupported value of type %T", columnValue)
+	}
+	field.Set(converted.Convert(field.Type()))
+	return nil
+}
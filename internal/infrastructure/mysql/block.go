@@ -4,9 +4,11 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"time"
 )
 
 // blockAdapter is a struct that acts as an adapter for interacting with
@@ -41,6 +43,42 @@ func (adapter *blockAdapter) FindAll(ctx context.Context) ([]*model.Block, error
 	return blocks, nil
 }
 
+// FindUpdatedSince retrieves the block records created or updated since the given time.
+// It takes a context and returns a slice of model.Block and an error if the operation fails.
+func (adapter *blockAdapter) FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Block, error) {
+	const query = `
+        SELECT * FROM block WHERE createdAt >= ? OR updatedAt >= ?;
+    `
+	var blocksDB []*BlockDB
+	if err := adapter.client.db.SelectContext(ctx, &blocksDB, query, since, since); err != nil {
+		return nil, err
+	}
+	var blocks []*model.Block
+	for _, blockDB := range blocksDB {
+		mappedBlock := blockDB.ToDomainModel()
+		blocks = append(blocks, &mappedBlock)
+	}
+	return blocks, nil
+}
+
+// Find retrieves a block record from the database by its UUID.
+// It takes a context and the block's UUID, and returns a model.Block and an error if the operation fails,
+// port.ErrNotFound if there is no such block.
+func (adapter *blockAdapter) Find(ctx context.Context, blockUUID string) (*model.Block, error) {
+	const query = `
+        SELECT * FROM block WHERE UUID = UUID_TO_BIN(?);
+    `
+	var blockDB BlockDB
+	if err := adapter.client.db.GetContext(ctx, &blockDB, query, blockUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	result := blockDB.ToDomainModel()
+	return &result, nil
+}
+
 // BlockDB is a struct representing the block database model.
 type BlockDB struct {
 	UUID        uuid.UUID      `db:"UUID"`
@@ -59,6 +97,7 @@ func (db *BlockDB) ToDomainModel() model.Block {
 		Name:        db.Name.String,
 		Description: db.Description.String,
 		Kind:        db.Kind.String,
+		Version:     documentVersion(db.CreatedAt, db.UpdatedAt),
 	}
 }
 
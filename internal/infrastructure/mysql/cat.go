@@ -4,9 +4,11 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"time"
 )
 
 // categoryAdapter is a struct that acts as an adapter for interacting with
@@ -41,14 +43,36 @@ func (adapter *categoryAdapter) FindAll(ctx context.Context) ([]*model.Category,
 	return categories, nil
 }
 
+// FindUpdatedSince retrieves the category records created or updated since the given time.
+// It takes a context and returns a slice of model.Category and an error if the operation fails.
+func (adapter *categoryAdapter) FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Category, error) {
+	const query = `
+        SELECT * FROM category WHERE createdAt >= ? OR updatedAt >= ?;
+    `
+	var categoriesDB []*CategoryDB
+	if err := adapter.client.db.SelectContext(ctx, &categoriesDB, query, since, since); err != nil {
+		return nil, err
+	}
+	var categories []*model.Category
+	for _, categoryDB := range categoriesDB {
+		mappedCategory := categoryDB.ToDomainModel()
+		categories = append(categories, &mappedCategory)
+	}
+	return categories, nil
+}
+
 // Find retrieves a category record from the database by its UUID.
-// It takes a context and the category's UUID, and returns a model.Category and an error if the operation fails.
+// It takes a context and the category's UUID, and returns a model.Category and an error if the operation fails,
+// port.ErrNotFound if there is no such category.
 func (adapter *categoryAdapter) Find(ctx context.Context, categoryUUID string) (*model.Category, error) {
 	const query = `
         SELECT * FROM category WHERE UUID = UUID_TO_BIN(?);
     `
 	var categoryDB CategoryDB
 	if err := adapter.client.db.GetContext(ctx, &categoryDB, query, categoryUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrNotFound
+		}
 		return nil, err
 	}
 	result := categoryDB.ToDomainModel()
@@ -75,6 +99,7 @@ func (db *CategoryDB) ToDomainModel() model.Category {
 		Parent: &model.Category{
 			ID: db.ParentID.String(),
 		},
+		Version: documentVersion(db.CreatedAt, db.UpdatedAt),
 	}
 }
 
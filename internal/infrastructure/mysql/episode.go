@@ -4,9 +4,12 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"time"
 )
 
 // episodeAdapter is a struct that acts as an adapter for interacting with
@@ -27,7 +30,7 @@ func NewEpisodeAdapter(client *client) port.Episode {
 // It takes a context and returns a slice of model.Episode and an error if the operation fails.
 func (adapter *episodeAdapter) FindAll(ctx context.Context) ([]*model.Episode, error) {
 	const query = `
-        SELECT * FROM episode;
+        SELECT * FROM episode WHERE deletedAt IS NULL;
     `
 	var episodesDB []*EpisodeDB
 	if err := adapter.client.db.SelectContext(ctx, &episodesDB, query); err != nil {
@@ -41,6 +44,111 @@ func (adapter *episodeAdapter) FindAll(ctx context.Context) ([]*model.Episode, e
 	return episodes, nil
 }
 
+// FindUpdatedSince retrieves the episode records created or updated since the given time.
+// It takes a context and returns a slice of model.Episode and an error if the operation fails.
+func (adapter *episodeAdapter) FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Episode, error) {
+	const query = `
+        SELECT * FROM episode WHERE (createdAt >= ? OR updatedAt >= ?) AND deletedAt IS NULL;
+    `
+	var episodesDB []*EpisodeDB
+	if err := adapter.client.db.SelectContext(ctx, &episodesDB, query, since, since); err != nil {
+		return nil, err
+	}
+	var episodes []*model.Episode
+	for _, episodeDB := range episodesDB {
+		mappedEpisode := episodeDB.ToDomainModel()
+		episodes = append(episodes, &mappedEpisode)
+	}
+	return episodes, nil
+}
+
+// Find retrieves a episode record from the database by its UUID.
+// It takes a context and the episode's UUID, and returns a model.Episode and an error if the operation fails,
+// port.ErrNotFound if there is no such episode.
+func (adapter *episodeAdapter) Find(ctx context.Context, episodeUUID string) (*model.Episode, error) {
+	const query = `
+        SELECT * FROM episode WHERE UUID = UUID_TO_BIN(?) AND deletedAt IS NULL;
+    `
+	var episodeDB EpisodeDB
+	if err := adapter.client.db.GetContext(ctx, &episodeDB, query, episodeUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	result := episodeDB.ToDomainModel()
+	return &result, nil
+}
+
+// FindDeletedSince retrieves the UUIDs of the episode records soft-deleted since the given time.
+// It takes a context and returns a slice of UUIDs and an error if the operation fails.
+func (adapter *episodeAdapter) FindDeletedSince(ctx context.Context, since time.Time) ([]string, error) {
+	const query = `
+        SELECT BIN_TO_UUID(UUID) FROM episode WHERE deletedAt >= ?;
+    `
+	var uuids []string
+	if err := adapter.client.db.SelectContext(ctx, &uuids, query, since); err != nil {
+		return nil, err
+	}
+	return uuids, nil
+}
+
+// FindIDs retrieves the UUIDs of all the episode records that are not soft-deleted.
+// It takes a context and returns a slice of UUIDs and an error if the operation fails.
+func (adapter *episodeAdapter) FindIDs(ctx context.Context) ([]string, error) {
+	const query = `
+        SELECT BIN_TO_UUID(UUID) FROM episode WHERE deletedAt IS NULL;
+    `
+	var uuids []string
+	if err := adapter.client.db.SelectContext(ctx, &uuids, query); err != nil {
+		return nil, err
+	}
+	return uuids, nil
+}
+
+// countChunkSize is the maximum number of programs whose episodes are counted by a single query.
+const countChunkSize = 1000
+
+// CountByProgram counts the episode records that are not soft-deleted of each of the given programs.
+// It takes a context and the programs' UUIDs, and returns the counts by program UUID, leaving out the programs
+// without episodes, and an error if the operation fails.
+func (adapter *episodeAdapter) CountByProgram(ctx context.Context, programUUIDs []string) (map[string]int, error) {
+	programs := make([][]byte, 0, len(programUUIDs))
+	for _, programUUID := range programUUIDs {
+		// A program that is not a UUID has no episodes
+		if parsed, err := uuid.Parse(programUUID); err == nil {
+			programs = append(programs, parsed[:])
+		}
+	}
+
+	counts := make(map[string]int)
+	// The programs are counted by chunks, keeping the number of placeholders of a query bounded
+	for start := 0; start < len(programs); start += countChunkSize {
+		end := start + countChunkSize
+		if end > len(programs) {
+			end = len(programs)
+		}
+		query, args, err := sqlx.In(`
+            SELECT BIN_TO_UUID(programUUID) AS programUUID, COUNT(*) AS count FROM episode
+            WHERE programUUID IN (?) AND deletedAt IS NULL GROUP BY programUUID;
+        `, programs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		var rows []struct {
+			ProgramUUID string `db:"programUUID"`
+			Count       int    `db:"count"`
+		}
+		if err := adapter.client.db.SelectContext(ctx, &rows, query, args...); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			counts[row.ProgramUUID] = row.Count
+		}
+	}
+	return counts, nil
+}
+
 // EpisodeDB is a struct representing the episode database model.
 type EpisodeDB struct {
 	UUID        uuid.UUID      `db:"UUID"`
@@ -50,6 +158,7 @@ type EpisodeDB struct {
 	ProgramID   uuid.UUID      `db:"programUUID"`
 	CreatedAt   sql.NullTime   `db:"createdAt"`
 	UpdatedAt   sql.NullTime   `db:"updatedAt"`
+	DeletedAt   sql.NullTime   `db:"deletedAt"`
 }
 
 // ToDomainModel converts an EpisodeDB database model to a model.Episode domain model.
@@ -61,6 +170,7 @@ func (db *EpisodeDB) ToDomainModel() model.Episode {
 		Description: db.Description.String,
 		Position:    db.Position,
 		ProgramID:   db.ProgramID.String(),
+		Version:     documentVersion(db.CreatedAt, db.UpdatedAt),
 	}
 }
 
@@ -0,0 +1,201 @@
+// Package mysql provides MySQL implementations of the persistence interfaces.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"log"
+	"strings"
+)
+
+// historyAdapter is a struct that acts as an adapter for the indexation history stored in the MySQL database.
+type historyAdapter struct {
+	client *client
+}
+
+// NewHistoryAdapter creates a new history adapter with the provided MySQL client.
+// It fails unless the history table has the columns of the migrations.
+func NewHistoryAdapter(client *client) port.History {
+	if err := checkSchema(client.db, "indexer_job", "id", "docsType", "mode", "triggerSource", "caller", "indexName",
+		"status", "reason", "errorMessage", "phase", "rowsRead", "documents", "invalid", "flushes", "indexed", "failures",
+		"removed", "staleSkipped", "dropped", "quality", "startedAt", "finishedAt"); err != nil {
+		log.Fatalf("could not use history table: %s", err.Error())
+	}
+	return &historyAdapter{
+		client: client,
+	}
+}
+
+// Save inserts the job record, or replaces the previous record of the job.
+func (adapter *historyAdapter) Save(ctx context.Context, job model.Job) error {
+	const query = `
+        REPLACE INTO indexer_job (id, docsType, mode, triggerSource, caller, indexName, status, reason, errorMessage,
+            phase, rowsRead, documents, invalid, flushes, indexed, failures, removed, staleSkipped, dropped, quality,
+            startedAt, finishedAt)
+        VALUES (:id, :docsType, :mode, :triggerSource, :caller, :indexName, :status, :reason, :errorMessage,
+            :phase, :rowsRead, :documents, :invalid, :flushes, :indexed, :failures, :removed, :staleSkipped, :dropped, :quality,
+            :startedAt, :finishedAt);
+    `
+	var jobDB JobDB
+	if err := jobDB.FromDomainModel(job); err != nil {
+		return err
+	}
+	_, err := adapter.client.db.NamedExecContext(ctx, query, jobDB)
+	return err
+}
+
+// Find retrieves the page of job records selected by the filter, newest first.
+// It returns the page and an error if the operation fails.
+func (adapter *historyAdapter) Find(ctx context.Context, filter model.JobFilter) (model.JobPage, error) {
+	where, args := historyConditions(filter)
+
+	page := model.JobPage{Jobs: []model.Job{}, Limit: filter.Limit, Offset: filter.Offset}
+	if err := adapter.client.db.GetContext(ctx, &page.Total, "SELECT COUNT(*) FROM indexer_job"+where+";", args...); err != nil {
+		return model.JobPage{}, err
+	}
+
+	query := "SELECT * FROM indexer_job" + where + " ORDER BY startedAt DESC, id LIMIT ? OFFSET ?;"
+	var jobsDB []*JobDB
+	if err := adapter.client.db.SelectContext(ctx, &jobsDB, query, append(args, filter.Limit, filter.Offset)...); err != nil {
+		return model.JobPage{}, err
+	}
+	for _, jobDB := range jobsDB {
+		page.Jobs = append(page.Jobs, jobDB.ToDomainModel())
+	}
+	return page, nil
+}
+
+// historyConditions returns the WHERE clause selecting the jobs matching the filter, along with its arguments.
+func historyConditions(filter model.JobFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	equalities := []struct{ column, value string }{
+		{"docsType", filter.DocsType},
+		{"mode", filter.Mode},
+		{"status", filter.Status},
+		{"triggerSource", filter.Trigger},
+		{"caller", filter.Caller},
+	}
+	for _, equality := range equalities {
+		if equality.value != "" {
+			conditions = append(conditions, equality.column+" = ?")
+			args = append(args, equality.value)
+		}
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "startedAt >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "startedAt < ?")
+		args = append(args, filter.To)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// JobDB is a struct representing the job database model.
+type JobDB struct {
+	ID           string         `db:"id"`
+	DocsType     string         `db:"docsType"`
+	Mode         string         `db:"mode"`
+	Trigger      string         `db:"triggerSource"`
+	Caller       string         `db:"caller"`
+	IndexName    string         `db:"indexName"`
+	Status       string         `db:"status"`
+	Reason       string         `db:"reason"`
+	ErrorMessage string         `db:"errorMessage"`
+	Phase        string         `db:"phase"`
+	RowsRead     int            `db:"rowsRead"`
+	Documents    int            `db:"documents"`
+	Invalid      int            `db:"invalid"`
+	Flushes      int            `db:"flushes"`
+	Indexed      int            `db:"indexed"`
+	Failures     int            `db:"failures"`
+	Removed      int            `db:"removed"`
+	Stale        int            `db:"staleSkipped"`
+	Dropped      int            `db:"dropped"`
+	Quality      sql.NullString `db:"quality"`
+	StartedAt    sql.NullTime   `db:"startedAt"`
+	FinishedAt   sql.NullTime   `db:"finishedAt"`
+}
+
+// ToDomainModel converts a JobDB database model to a model.Job domain model.
+// It returns the corresponding model.Job.
+func (db *JobDB) ToDomainModel() model.Job {
+	job := model.Job{
+		ID:        db.ID,
+		DocsType:  db.DocsType,
+		Mode:      db.Mode,
+		Trigger:   db.Trigger,
+		Caller:    db.Caller,
+		IndexName: db.IndexName,
+		Status:    db.Status,
+		Reason:    db.Reason,
+		Error:     db.ErrorMessage,
+		Progress: model.Progress{
+			Phase:     db.Phase,
+			RowsRead:  db.RowsRead,
+			Documents: db.Documents,
+			Invalid:   db.Invalid,
+			Flushes:   db.Flushes,
+			Indexed:   db.Indexed,
+			Failures:  db.Failures,
+			Removed:   db.Removed,
+			Stale:     db.Stale,
+			Dropped:   db.Dropped,
+		},
+		StartedAt: db.StartedAt.Time,
+	}
+	if db.FinishedAt.Valid {
+		finishedAt := db.FinishedAt.Time
+		job.FinishedAt = &finishedAt
+	}
+	if db.Quality.Valid {
+		// The report is informative: a record whose report cannot be read is still returned
+		_ = json.Unmarshal([]byte(db.Quality.String), &job.Quality)
+	}
+	return job
+}
+
+// FromDomainModel converts a model.Job domain model to a JobDB database model.
+// It sets the fields of the JobDB based on the given model.Job, and returns an error if its data-quality report
+// cannot be encoded.
+func (db *JobDB) FromDomainModel(domain model.Job) error {
+	db.ID = domain.ID
+	db.DocsType = domain.DocsType
+	db.Mode = domain.Mode
+	db.Trigger = domain.Trigger
+	db.Caller = domain.Caller
+	db.IndexName = domain.IndexName
+	db.Status = domain.Status
+	db.Reason = domain.Reason
+	db.ErrorMessage = domain.Error
+	db.Phase = domain.Progress.Phase
+	db.RowsRead = domain.Progress.RowsRead
+	db.Documents = domain.Progress.Documents
+	db.Invalid = domain.Progress.Invalid
+	db.Flushes = domain.Progress.Flushes
+	db.Indexed = domain.Progress.Indexed
+	db.Failures = domain.Progress.Failures
+	db.Removed = domain.Progress.Removed
+	db.Stale = domain.Progress.Stale
+	db.Dropped = domain.Progress.Dropped
+	db.StartedAt = sql.NullTime{Time: domain.StartedAt, Valid: true}
+	if domain.FinishedAt != nil {
+		db.FinishedAt = sql.NullTime{Time: *domain.FinishedAt, Valid: true}
+	}
+	if len(domain.Quality) > 0 {
+		quality, err := json.Marshal(domain.Quality)
+		if err != nil {
+			return err
+		}
+		db.Quality = sql.NullString{String: string(quality), Valid: true}
+	}
+	return nil
+}
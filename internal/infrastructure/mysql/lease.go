@@ -0,0 +1,59 @@
+// Package mysql provides MySQL implementations of the persistence interfaces.
+package mysql
+
+import (
+	"context"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"log"
+	"time"
+)
+
+// leaseAdapter is a struct that acts as an adapter for the leases stored in the MySQL database.
+type leaseAdapter struct {
+	client *client
+}
+
+// NewLeaseAdapter creates a new lease adapter with the provided MySQL client.
+// It fails unless the lease table has the columns of the migrations.
+func NewLeaseAdapter(client *client) port.Lease {
+	if err := checkSchema(client.db, "indexer_lease", "name", "holder", "expiresAt"); err != nil {
+		log.Fatalf("could not use lease table: %s", err.Error())
+	}
+	return &leaseAdapter{
+		client: client,
+	}
+}
+
+// Acquire takes the lease if it is free or expired, or renews it if the holder already owns it.
+// Expiry is evaluated with the database clock so that instances with skewed clocks agree on it.
+func (adapter *leaseAdapter) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	// The holder assignment is evaluated first, so the expiry is only extended when the lease was taken
+	const upsert = `
+        INSERT INTO indexer_lease (name, holder, expiresAt)
+        VALUES (?, ?, NOW(3) + INTERVAL ? MICROSECOND)
+        ON DUPLICATE KEY UPDATE
+            holder = IF(holder = VALUES(holder) OR expiresAt < NOW(3), VALUES(holder), holder),
+            expiresAt = IF(holder = VALUES(holder), VALUES(expiresAt), expiresAt);
+    `
+	if _, err := adapter.client.db.ExecContext(ctx, upsert, name, holder, ttl.Microseconds()); err != nil {
+		return false, err
+	}
+
+	const query = `
+        SELECT holder FROM indexer_lease WHERE name = ?;
+    `
+	var currentHolder string
+	if err := adapter.client.db.GetContext(ctx, &currentHolder, query, name); err != nil {
+		return false, err
+	}
+	return currentHolder == holder, nil
+}
+
+// Release gives up the lease if it is owned by the holder, letting another instance acquire it immediately.
+func (adapter *leaseAdapter) Release(ctx context.Context, name, holder string) error {
+	const query = `
+        UPDATE indexer_lease SET expiresAt = NOW(3) WHERE name = ? AND holder = ?;
+    `
+	_, err := adapter.client.db.ExecContext(ctx, query, name, holder)
+	return err
+}
@@ -4,6 +4,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
@@ -41,6 +42,24 @@ func (adapter *mediaAdapter) FindAll(ctx context.Context) ([]*model.Media, error
 	return media, nil
 }
 
+// Find retrieves a media record from the database by its UUID.
+// It takes a context and the media's UUID, and returns a model.Media and an error if the operation fails,
+// port.ErrNotFound if there is no such media.
+func (adapter *mediaAdapter) Find(ctx context.Context, mediaUUID string) (*model.Media, error) {
+	const query = `
+        SELECT * FROM media WHERE UUID = UUID_TO_BIN(?);
+    `
+	var mediaDB MediaDB
+	if err := adapter.client.db.GetContext(ctx, &mediaDB, query, mediaUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	result := mediaDB.ToDomainModel()
+	return &result, nil
+}
+
 // MediaDB is a struct representing the media database model.
 type MediaDB struct {
 	UUID       uuid.UUID      `db:"UUID"`
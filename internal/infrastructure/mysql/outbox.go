@@ -0,0 +1,125 @@
+// Package mysql provides MySQL implementations of the persistence interfaces.
+package mysql
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+)
+
+// Operations of the outbox entries.
+const (
+	outboxUpsert = "upsert" // The entity was created or updated
+	outboxDelete = "delete" // The entity was deleted
+)
+
+// outboxAdapter is a struct that acts as an adapter for the outbox stored in the MySQL database.
+// The index_outbox table is owned by the writing services, which create it along with their own tables:
+//
+//	CREATE TABLE index_outbox (
+//	    id          BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+//	    docsType    VARCHAR(32)  NOT NULL,
+//	    entityId    VARCHAR(36)  NOT NULL,
+//	    operation   VARCHAR(16)  NOT NULL,
+//	    createdAt   DATETIME(3)  NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+//	    processedAt DATETIME(3)  NULL,
+//	    attempts    INT          NOT NULL DEFAULT 0,
+//	    lastError   TEXT         NULL,
+//	    INDEX index_outbox_pending (processedAt, attempts, id)
+//	);
+type outboxAdapter struct {
+	client *client
+}
+
+// NewOutboxAdapter creates a new outbox adapter with the provided MySQL client.
+func NewOutboxAdapter(client *client) port.Outbox {
+	return &outboxAdapter{
+		client: client,
+	}
+}
+
+// Process passes the oldest pending entries to the handler within a transaction holding their locks,
+// skipping the entries locked by other instances. Entries are marked once the transaction commits,
+// so entries handled by an instance that stops before are processed again.
+func (adapter *outboxAdapter) Process(ctx context.Context, limit, maxAttempts int, handler port.CommandHandler) (int, error) {
+	tx, err := adapter.client.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const query = `
+        SELECT id, docsType, entityId, operation FROM index_outbox
+        WHERE processedAt IS NULL AND attempts < ?
+        ORDER BY id
+        LIMIT ?
+        FOR UPDATE SKIP LOCKED;
+    `
+	var entriesDB []*OutboxEntryDB
+	if err := tx.SelectContext(ctx, &entriesDB, query, maxAttempts, limit); err != nil {
+		return 0, err
+	}
+
+	var processed []int64
+	for _, entryDB := range entriesDB {
+		if err := handler(ctx, entryDB.ToCommand()); err != nil {
+			if err := markFailed(ctx, tx, entryDB.ID, err); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		processed = append(processed, entryDB.ID)
+	}
+	if err := markProcessed(ctx, tx, processed); err != nil {
+		return 0, err
+	}
+	return len(entriesDB), tx.Commit()
+}
+
+// markProcessed marks the entries with the given identifiers as processed.
+func markProcessed(ctx context.Context, tx *sqlx.Tx, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query, args, err := sqlx.In(`
+        UPDATE index_outbox SET processedAt = NOW(3), attempts = attempts + 1, lastError = NULL WHERE id IN (?);
+    `, ids)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// markFailed records a failed attempt to process an entry.
+func markFailed(ctx context.Context, tx *sqlx.Tx, id int64, cause error) error {
+	const query = `
+        UPDATE index_outbox SET attempts = attempts + 1, lastError = ? WHERE id = ?;
+    `
+	_, err := tx.ExecContext(ctx, query, cause.Error(), id)
+	return err
+}
+
+// OutboxEntryDB is a struct representing the outbox entry database model.
+type OutboxEntryDB struct {
+	ID        int64  `db:"id"`
+	DocsType  string `db:"docsType"`
+	EntityID  string `db:"entityId"`
+	Operation string `db:"operation"`
+}
+
+// ToCommand converts an OutboxEntryDB database model to the model.Command applying it.
+// An unknown operation results in a command of unknown type, which is rejected.
+func (db *OutboxEntryDB) ToCommand() model.Command {
+	command := model.Command{DocsType: db.DocsType, ID: db.EntityID}
+	switch db.Operation {
+	case outboxUpsert:
+		command.Type = model.CommandEntityUpserted
+	case outboxDelete:
+		command.Type = model.CommandEntityDeleted
+	default:
+		command.Type = db.Operation
+	}
+	return command
+}
@@ -4,9 +4,12 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"time"
 )
 
 // programAdapter is a struct that acts as an adapter for interacting with
@@ -27,7 +30,7 @@ func NewProgramAdapter(client *client) port.Program {
 // It takes a context and returns a slice of model.Program and an error if the operation fails.
 func (adapter *programAdapter) FindAll(ctx context.Context) ([]*model.Program, error) {
 	const query = `
-        SELECT * FROM program;
+        SELECT * FROM program WHERE deletedAt IS NULL;
     `
 	var programsDB []*ProgramDB
 	if err := adapter.client.db.SelectContext(ctx, &programsDB, query); err != nil {
@@ -41,6 +44,104 @@ func (adapter *programAdapter) FindAll(ctx context.Context) ([]*model.Program, e
 	return programs, nil
 }
 
+// FindUpdatedSince retrieves the program records created or updated since the given time.
+// It takes a context and returns a slice of model.Program and an error if the operation fails.
+func (adapter *programAdapter) FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Program, error) {
+	const query = `
+        SELECT * FROM program WHERE (createdAt >= ? OR updatedAt >= ?) AND deletedAt IS NULL;
+    `
+	var programsDB []*ProgramDB
+	if err := adapter.client.db.SelectContext(ctx, &programsDB, query, since, since); err != nil {
+		return nil, err
+	}
+	var programs []*model.Program
+	for _, programDB := range programsDB {
+		mappedProgram := programDB.ToDomainModel()
+		programs = append(programs, &mappedProgram)
+	}
+	return programs, nil
+}
+
+// Find retrieves a program record from the database by its UUID.
+// It takes a context and the program's UUID, and returns a model.Program and an error if the operation fails,
+// port.ErrNotFound if there is no such program.
+func (adapter *programAdapter) Find(ctx context.Context, programUUID string) (*model.Program, error) {
+	const query = `
+        SELECT * FROM program WHERE UUID = UUID_TO_BIN(?) AND deletedAt IS NULL;
+    `
+	var programDB ProgramDB
+	if err := adapter.client.db.GetContext(ctx, &programDB, query, programUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	result := programDB.ToDomainModel()
+	return &result, nil
+}
+
+// FindDeletedSince retrieves the UUIDs of the program records soft-deleted since the given time.
+// It takes a context and returns a slice of UUIDs and an error if the operation fails.
+func (adapter *programAdapter) FindDeletedSince(ctx context.Context, since time.Time) ([]string, error) {
+	const query = `
+        SELECT BIN_TO_UUID(UUID) FROM program WHERE deletedAt >= ?;
+    `
+	var uuids []string
+	if err := adapter.client.db.SelectContext(ctx, &uuids, query, since); err != nil {
+		return nil, err
+	}
+	return uuids, nil
+}
+
+// FindIDs retrieves the UUIDs of all the program records that are not soft-deleted.
+// It takes a context and returns a slice of UUIDs and an error if the operation fails.
+func (adapter *programAdapter) FindIDs(ctx context.Context) ([]string, error) {
+	const query = `
+        SELECT BIN_TO_UUID(UUID) FROM program WHERE deletedAt IS NULL;
+    `
+	var uuids []string
+	if err := adapter.client.db.SelectContext(ctx, &uuids, query); err != nil {
+		return nil, err
+	}
+	return uuids, nil
+}
+
+// existingChunkSize is the maximum number of programs looked up by a single query.
+const existingChunkSize = 1000
+
+// FindExistingIDs retrieves the UUIDs of the given program records that exist and are not soft-deleted.
+// It takes a context and the programs' UUIDs, and returns a slice of UUIDs and an error if the operation fails.
+func (adapter *programAdapter) FindExistingIDs(ctx context.Context, programUUIDs []string) ([]string, error) {
+	programs := make([][]byte, 0, len(programUUIDs))
+	for _, programUUID := range programUUIDs {
+		// A program that is not a UUID does not exist
+		if parsed, err := uuid.Parse(programUUID); err == nil {
+			programs = append(programs, parsed[:])
+		}
+	}
+
+	var uuids []string
+	// The programs are looked up by chunks, keeping the number of placeholders of a query bounded
+	for start := 0; start < len(programs); start += existingChunkSize {
+		end := start + existingChunkSize
+		if end > len(programs) {
+			end = len(programs)
+		}
+		query, args, err := sqlx.In(`
+            SELECT BIN_TO_UUID(UUID) FROM program WHERE UUID IN (?) AND deletedAt IS NULL;
+        `, programs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		var chunk []string
+		if err := adapter.client.db.SelectContext(ctx, &chunk, query, args...); err != nil {
+			return nil, err
+		}
+		uuids = append(uuids, chunk...)
+	}
+	return uuids, nil
+}
+
 // ProgramDB is a struct representing the program database model.
 type ProgramDB struct {
 	UUID        uuid.UUID      `db:"UUID"`
@@ -48,6 +149,7 @@ type ProgramDB struct {
 	Description sql.NullString `db:"description"`
 	CreatedAt   sql.NullTime   `db:"createdAt"`
 	UpdatedAt   sql.NullTime   `db:"updatedAt"`
+	DeletedAt   sql.NullTime   `db:"deletedAt"`
 }
 
 // ToDomainModel converts a ProgramDB database model to a model.Program domain model.
@@ -57,6 +159,7 @@ func (db *ProgramDB) ToDomainModel() model.Program {
 		ID:          db.UUID.String(),
 		Name:        db.Name.String,
 		Description: db.Description.String,
+		Version:     documentVersion(db.CreatedAt, db.UpdatedAt),
 	}
 }
 
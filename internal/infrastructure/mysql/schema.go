@@ -0,0 +1,38 @@
+// Package mysql provides MySQL implementations of the persistence interfaces.
+package mysql
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+// checkSchema checks that the table exists with the given columns. The tables owned by the indexer are created and
+// altered by the migrations of the migrations directory, applied before the service starts, never by the service:
+// its database user needs no schema privilege.
+func checkSchema(db *sqlx.DB, table string, columns ...string) error {
+	const query = `
+        SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?;
+    `
+	var existing []string
+	if err := db.Select(&existing, query, table); err != nil {
+		return fmt.Errorf("could not read the schema of table %s: %w", table, err)
+	}
+	if len(existing) == 0 {
+		return fmt.Errorf("table %s does not exist, apply the migrations", table)
+	}
+	found := make(map[string]bool, len(existing))
+	for _, column := range existing {
+		found[strings.ToLower(column)] = true
+	}
+	var missing []string
+	for _, column := range columns {
+		if !found[strings.ToLower(column)] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("table %s lacks the columns %s, apply the migrations", table, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package mysql provides MySQL implementations of the persistence interfaces.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"log"
+)
+
+// searchTermsAdapter is a struct that acts as an adapter for the search terms stored in the MySQL database.
+type searchTermsAdapter struct {
+	client *client
+}
+
+// NewSearchTermsAdapter creates a new search terms adapter with the provided MySQL client.
+// It fails unless the search terms table has the columns of the migrations.
+func NewSearchTermsAdapter(client *client) port.SearchTerms {
+	if err := checkSchema(client.db, "indexer_search_terms", "id", "synonyms", "stopwords", "updatedAt"); err != nil {
+		log.Fatalf("could not use search terms table: %s", err.Error())
+	}
+	return &searchTermsAdapter{
+		client: client,
+	}
+}
+
+// Find retrieves the search terms, empty if none were saved.
+func (adapter *searchTermsAdapter) Find(ctx context.Context) (model.SearchTerms, error) {
+	const query = `
+        SELECT synonyms, stopwords, updatedAt FROM indexer_search_terms WHERE id = 1;
+    `
+	var termsDB SearchTermsDB
+	err := adapter.client.db.GetContext(ctx, &termsDB, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.SearchTerms{Synonyms: []string{}, Stopwords: []string{}}, nil
+	}
+	if err != nil {
+		return model.SearchTerms{}, err
+	}
+	return termsDB.ToDomainModel()
+}
+
+// Save replaces the search terms.
+func (adapter *searchTermsAdapter) Save(ctx context.Context, terms model.SearchTerms) error {
+	const query = `
+        REPLACE INTO indexer_search_terms (id, synonyms, stopwords, updatedAt)
+        VALUES (1, :synonyms, :stopwords, :updatedAt);
+    `
+	var termsDB SearchTermsDB
+	if err := termsDB.FromDomainModel(terms); err != nil {
+		return err
+	}
+	_, err := adapter.client.db.NamedExecContext(ctx, query, termsDB)
+	return err
+}
+
+// SearchTermsDB is a struct representing the search terms database model.
+type SearchTermsDB struct {
+	Synonyms  string       `db:"synonyms"`
+	Stopwords string       `db:"stopwords"`
+	UpdatedAt sql.NullTime `db:"updatedAt"`
+}
+
+// ToDomainModel converts a SearchTermsDB database model to a model.SearchTerms domain model.
+// It returns an error if the lists cannot be decoded.
+func (db *SearchTermsDB) ToDomainModel() (model.SearchTerms, error) {
+	terms := model.SearchTerms{UpdatedAt: db.UpdatedAt.Time}
+	if err := json.Unmarshal([]byte(db.Synonyms), &terms.Synonyms); err != nil {
+		return model.SearchTerms{}, err
+	}
+	if err := json.Unmarshal([]byte(db.Stopwords), &terms.Stopwords); err != nil {
+		return model.SearchTerms{}, err
+	}
+	return terms, nil
+}
+
+// FromDomainModel converts a model.SearchTerms domain model to a SearchTermsDB database model.
+// It returns an error if the lists cannot be encoded.
+func (db *SearchTermsDB) FromDomainModel(domain model.SearchTerms) error {
+	synonyms, err := json.Marshal(domain.Synonyms)
+	if err != nil {
+		return err
+	}
+	stopwords, err := json.Marshal(domain.Stopwords)
+	if err != nil {
+		return err
+	}
+	db.Synonyms = string(synonyms)
+	db.Stopwords = string(stopwords)
+	db.UpdatedAt = sql.NullTime{Time: domain.UpdatedAt, Valid: true}
+	return nil
+}
@@ -4,9 +4,11 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"time"
 )
 
 // tagAdapter is a struct that acts as an adapter for interacting with
@@ -41,6 +43,42 @@ func (adapter *tagAdapter) FindAll(ctx context.Context) ([]*model.Tag, error) {
 	return tags, nil
 }
 
+// FindUpdatedSince retrieves the tag records created or updated since the given time.
+// It takes a context and returns a slice of model.Tag and an error if the operation fails.
+func (adapter *tagAdapter) FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Tag, error) {
+	const query = `
+        SELECT * FROM tag WHERE createdAt >= ? OR updatedAt >= ?;
+    `
+	var tagsDB []*TagDB
+	if err := adapter.client.db.SelectContext(ctx, &tagsDB, query, since, since); err != nil {
+		return nil, err
+	}
+	var tags []*model.Tag
+	for _, tagDB := range tagsDB {
+		mappedTag := tagDB.ToDomainModel()
+		tags = append(tags, &mappedTag)
+	}
+	return tags, nil
+}
+
+// Find retrieves a tag record from the database by its UUID.
+// It takes a context and the tag's UUID, and returns a model.Tag and an error if the operation fails,
+// port.ErrNotFound if there is no such tag.
+func (adapter *tagAdapter) Find(ctx context.Context, tagUUID string) (*model.Tag, error) {
+	const query = `
+        SELECT * FROM tag WHERE UUID = UUID_TO_BIN(?);
+    `
+	var tagDB TagDB
+	if err := adapter.client.db.GetContext(ctx, &tagDB, query, tagUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	result := tagDB.ToDomainModel()
+	return &result, nil
+}
+
 // TagDB is a struct representing the tag database model.
 type TagDB struct {
 	UUID        uuid.UUID      `db:"UUID"`
@@ -57,6 +95,7 @@ func (db *TagDB) ToDomainModel() model.Tag {
 		ID:          db.UUID.String(),
 		Name:        db.Name.String,
 		Description: db.Description.String,
+		Version:     documentVersion(db.CreatedAt, db.UpdatedAt),
 	}
 }
 
@@ -0,0 +1,17 @@
+// Package mysql provides MySQL implementations of the persistence interfaces.
+package mysql
+
+import "database/sql"
+
+// documentVersion returns the version of the document of a record, in microseconds since the Unix epoch
+// of its last update, or of its creation if it was never updated. It returns zero if neither is known.
+func documentVersion(createdAt, updatedAt sql.NullTime) int64 {
+	switch {
+	case updatedAt.Valid:
+		return updatedAt.Time.UnixMicro()
+	case createdAt.Valid:
+		return createdAt.Time.UnixMicro()
+	default:
+		return 0
+	}
+}
@@ -4,9 +4,11 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	"time"
 )
 
 // wallAdapter is a struct that acts as an adapter for interacting with
@@ -41,6 +43,42 @@ func (adapter *wallAdapter) FindAll(ctx context.Context) ([]*model.Wall, error)
 	return walls, nil
 }
 
+// FindUpdatedSince retrieves the wall records created or updated since the given time.
+// It takes a context and returns a slice of model.Wall and an error if the operation fails.
+func (adapter *wallAdapter) FindUpdatedSince(ctx context.Context, since time.Time) ([]*model.Wall, error) {
+	const query = `
+        SELECT * FROM wall WHERE createdAt >= ? OR updatedAt >= ?;
+    `
+	var wallsDB []*WallDB
+	if err := adapter.client.db.SelectContext(ctx, &wallsDB, query, since, since); err != nil {
+		return nil, err
+	}
+	var walls []*model.Wall
+	for _, wallDB := range wallsDB {
+		mappedWall := wallDB.ToDomainModel()
+		walls = append(walls, &mappedWall)
+	}
+	return walls, nil
+}
+
+// Find retrieves a wall record from the database by its UUID.
+// It takes a context and the wall's UUID, and returns a model.Wall and an error if the operation fails,
+// port.ErrNotFound if there is no such wall.
+func (adapter *wallAdapter) Find(ctx context.Context, wallUUID string) (*model.Wall, error) {
+	const query = `
+        SELECT * FROM wall WHERE UUID = UUID_TO_BIN(?);
+    `
+	var wallDB WallDB
+	if err := adapter.client.db.GetContext(ctx, &wallDB, query, wallUUID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrNotFound
+		}
+		return nil, err
+	}
+	result := wallDB.ToDomainModel()
+	return &result, nil
+}
+
 // WallDB is a struct representing the wall database model.
 type WallDB struct {
 	UUID        uuid.UUID      `db:"UUID"`
@@ -57,6 +95,7 @@ func (db *WallDB) ToDomainModel() model.Wall {
 		ID:          db.UUID.String(),
 		Name:        db.Name.String,
 		Description: db.Description.String,
+		Version:     documentVersion(db.CreatedAt, db.UpdatedAt),
 	}
 }
 
@@ -0,0 +1,28 @@
+// Package nats provides NATS implementations of the messaging interfaces.
+package nats
+
+import (
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	natsio "github.com/nats-io/nats.go"
+)
+
+// client holds a connection to the NATS server along with the messaging configuration of the application.
+type client struct {
+	conn   *natsio.Conn
+	config configuration.NatsConfig
+}
+
+// NewClient connects to the NATS server using the provided configuration.
+// The connection reconnects on its own after a network failure.
+func NewClient(config configuration.NatsConfig) (*client, error) {
+	conn, err := natsio.Connect(config.URL, natsio.Name("podcaster-indexer-api"), natsio.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &client{conn: conn, config: config}, nil
+}
+
+// Close drains the connection, letting pending messages be processed before closing it.
+func (c *client) Close() error {
+	return c.conn.Drain()
+}
@@ -0,0 +1,135 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"strconv"
+)
+
+// Headers added to the dead-lettered commands.
+const (
+	deadLetterReasonHeader     = "Dead-Letter-Reason"
+	deadLetterSubjectHeader    = "Dead-Letter-Subject"
+	deadLetterDeliveriesHeader = "Dead-Letter-Deliveries"
+)
+
+// consumerAdapter is a struct that acts as an adapter consuming the indexation commands from a JetStream stream.
+type consumerAdapter struct {
+	client *client
+}
+
+// NewConsumerAdapter creates a new consumer adapter with the provided NATS client.
+// The replicas share a durable pull consumer, so each command is processed by a single replica.
+func NewConsumerAdapter(client *client) port.Consumer {
+	return &consumerAdapter{
+		client: client,
+	}
+}
+
+// Consume passes the commands of the stream to the handler until the context is done.
+// The stream is created if it does not exist yet, and the durable consumer is created or updated.
+func (adapter *consumerAdapter) Consume(ctx context.Context, handler port.CommandHandler) error {
+	config := adapter.client.config.Consumer
+	js, err := jetstream.New(adapter.client.conn)
+	if err != nil {
+		return err
+	}
+
+	stream, err := js.Stream(ctx, config.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		stream, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: config.Stream, Subjects: []string{config.Subject}})
+	}
+	if err != nil {
+		return fmt.Errorf("could not get stream %s: %w", config.Stream, err)
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       config.Durable,
+		FilterSubject: config.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       config.AckWait,
+		MaxDeliver:    config.MaxDeliver,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create consumer %s: %w", config.Durable, err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		adapter.handle(ctx, msg, handler)
+	})
+	if err != nil {
+		return err
+	}
+	defer consumeCtx.Stop()
+
+	log.Info().Str("stream", config.Stream).Str("consumer", config.Durable).Msg("consuming indexation commands")
+	<-ctx.Done()
+	return nil
+}
+
+// handle processes a single message: it is acknowledged once handled, redelivered after a delay when the handler fails,
+// and dead-lettered when it is invalid or on its last delivery.
+func (adapter *consumerAdapter) handle(ctx context.Context, msg jetstream.Msg, handler port.CommandHandler) {
+	config := adapter.client.config.Consumer
+	var deliveries uint64
+	if metadata, err := msg.Metadata(); err == nil {
+		deliveries = metadata.NumDelivered
+	}
+	logger := log.With().Str("subject", msg.Subject()).Uint64("deliveries", deliveries).Logger()
+
+	var command model.Command
+	if err := json.Unmarshal(msg.Data(), &command); err != nil {
+		adapter.deadLetter(logger, msg, deliveries, fmt.Errorf("%w: %s", model.ErrInvalidCommand, err))
+		return
+	}
+	logger = logger.With().Str("command", command.Type).Str("docsType", command.DocsType).Str("id", command.ID).Logger()
+
+	// The command must be processed before it is redelivered to another replica
+	handlerCtx, cancel := context.WithTimeout(logger.WithContext(ctx), config.AckWait)
+	defer cancel()
+	err := handler(handlerCtx, command)
+	switch {
+	case err == nil:
+		if err := msg.Ack(); err != nil {
+			logger.Warn().Err(err).Msg("could not acknowledge command")
+		}
+	case errors.Is(err, model.ErrInvalidCommand), config.MaxDeliver > 0 && deliveries >= uint64(config.MaxDeliver):
+		adapter.deadLetter(logger, msg, deliveries, err)
+	default:
+		logger.Warn().Err(err).Msg("command failed, redelivery requested")
+		if err := msg.NakWithDelay(config.RetryDelay); err != nil {
+			logger.Warn().Err(err).Msg("could not request command redelivery")
+		}
+	}
+}
+
+// deadLetter copies the message to the dead-letter subject along with the reason, then terminates its delivery.
+// The message is redelivered instead if it could not be copied.
+func (adapter *consumerAdapter) deadLetter(logger zerolog.Logger, msg jetstream.Msg, deliveries uint64, reason error) {
+	deadLetter := &natsio.Msg{
+		Subject: adapter.client.config.Consumer.DeadLetterSubject,
+		Data:    msg.Data(),
+		Header:  natsio.Header{},
+	}
+	deadLetter.Header.Set(deadLetterReasonHeader, reason.Error())
+	deadLetter.Header.Set(deadLetterSubjectHeader, msg.Subject())
+	deadLetter.Header.Set(deadLetterDeliveriesHeader, strconv.FormatUint(deliveries, 10))
+
+	if err := adapter.client.conn.PublishMsg(deadLetter); err != nil {
+		logger.Error().Err(err).Msg("could not dead-letter command")
+		_ = msg.Nak()
+		return
+	}
+	logger.Error().Err(reason).Msg("command dead-lettered")
+	if err := msg.Term(); err != nil {
+		logger.Warn().Err(err).Msg("could not terminate command")
+	}
+}
@@ -0,0 +1,50 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/port"
+	natsio "github.com/nats-io/nats.go"
+	"strconv"
+)
+
+// Headers sent along with every event.
+const (
+	msgIDHeader   = "Nats-Msg-Id"    // Identifier of the event, used by JetStream to discard duplicates
+	versionHeader = "Schema-Version" // Version of the schema of the event
+)
+
+// publisherAdapter is a struct that acts as an adapter publishing the index events on NATS.
+type publisherAdapter struct {
+	client *client
+}
+
+// NewPublisherAdapter creates a new publisher adapter with the provided NATS client.
+// Events are published on "<prefix>.v<version>.<type>", e.g. "indexer.v1.index.promoted".
+func NewPublisherAdapter(client *client) port.Publisher {
+	return &publisherAdapter{
+		client: client,
+	}
+}
+
+// Publish sends the event and waits for the server to acknowledge the reception of the buffered messages.
+func (adapter *publisherAdapter) Publish(ctx context.Context, event model.IndexEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %w", err)
+	}
+	msg := &natsio.Msg{
+		Subject: fmt.Sprintf("%s.v%d.%s", adapter.client.config.SubjectPrefix, event.Version, event.Type),
+		Data:    data,
+		Header:  natsio.Header{},
+	}
+	msg.Header.Set(msgIDHeader, event.ID)
+	msg.Header.Set(versionHeader, strconv.Itoa(event.Version))
+
+	if err := adapter.client.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return adapter.client.conn.FlushWithContext(ctx)
+}
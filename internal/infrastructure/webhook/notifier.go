@@ -0,0 +1,235 @@
+// Package webhook provides the delivery of indexation notifications to outbound HTTP webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/pkg/retry"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers sent along with every notification.
+const (
+	SignatureHeader = "X-Indexer-Signature" // HMAC-SHA256 of the timestamp and the body, see Sign
+	TimestampHeader = "X-Indexer-Timestamp" // Unix time of the attempt, in seconds
+	EventHeader     = "X-Indexer-Event"     // Type of the notification
+	DeliveryHeader  = "X-Indexer-Delivery"  // Identifier of the delivery, identical across retries
+)
+
+// maxDeliveries is the number of deliveries kept in the delivery log.
+const maxDeliveries = 200
+
+// defaultRetryDelay is the delay between two attempts when none is configured.
+const defaultRetryDelay = time.Second
+
+// Notifier delivers notifications to the configured webhooks in the background,
+// retrying failed attempts and recording every delivery in a bounded log.
+type Notifier struct {
+	client *http.Client
+	config configuration.WebhooksConfig
+	wg     sync.WaitGroup
+
+	mu         sync.RWMutex
+	deliveries []*model.WebhookDelivery // Oldest first
+}
+
+// NewNotifier creates a notifier for the configured webhooks. A secret is required as soon as a webhook is configured,
+// the receivers could not authenticate payloads signed with an empty key.
+func NewNotifier(config configuration.WebhooksConfig) (*Notifier, error) {
+	if len(config.Endpoints) > 0 && strings.TrimSpace(config.Secret) == "" {
+		return nil, errors.New("webhook secret is required to sign the notifications")
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaultRetryDelay
+	}
+	return &Notifier{
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+	}, nil
+}
+
+// Notify starts delivering the notification to the webhooks subscribed to its type and returns without waiting for them.
+// Deliveries are not bound to the context, which only provides the logger.
+func (n *Notifier) Notify(ctx context.Context, notification model.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("could not marshal notification: %w", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, endpoint := range n.config.Endpoints {
+		if !subscribes(endpoint, notification.Type) {
+			continue
+		}
+		delivery := n.track(notification, endpoint.URL)
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			n.deliver(ctx, delivery, body)
+		}()
+	}
+	return nil
+}
+
+// Wait blocks until the pending deliveries are completed.
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+// Drain waits for the pending deliveries to complete, until the context is done. The deliveries still pending then
+// are logged, so that the notifications lost by a shutdown can be sent again, and reported as an error.
+func (n *Notifier) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	pending := 0
+	for _, delivery := range n.deliveries {
+		if delivery.Status != model.DeliveryPending {
+			continue
+		}
+		pending++
+		log.Error().Str("delivery", delivery.ID).Str("url", delivery.URL).Str("notification", delivery.Type).
+			Str("notificationId", delivery.NotificationID).Msg("webhook delivery abandoned")
+	}
+	return fmt.Errorf("%d webhook deliveries abandoned: %w", pending, ctx.Err())
+}
+
+// Deliveries returns the most recent deliveries, newest first.
+func (n *Notifier) Deliveries() []model.WebhookDelivery {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	deliveries := make([]model.WebhookDelivery, 0, len(n.deliveries))
+	for i := len(n.deliveries) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, *n.deliveries[i])
+	}
+	return deliveries
+}
+
+// Sign returns the signature of a notification body sent at the given Unix time, in the format of the signature header.
+// Receivers recompute it with the shared secret to authenticate the notification.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver posts the body to the webhook of the delivery, retrying failed attempts, and records the outcome.
+func (n *Notifier) deliver(ctx context.Context, delivery *model.WebhookDelivery, body []byte) {
+	var statusCode int
+	_, attempts, err := retry.ExecuteWithBackoffRetryContext(ctx, func() (interface{}, error) {
+		var err error
+		statusCode, err = n.post(ctx, delivery, body)
+		return nil, err
+	}, n.config.MaxRetries, n.config.RetryDelay)
+
+	logger := log.Ctx(ctx).With().Str("delivery", delivery.ID).Str("url", delivery.URL).Str("notification", delivery.Type).Logger()
+	if err != nil {
+		logger.Error().Err(err).Int("attempts", attempts).Msg("webhook delivery failed")
+	} else {
+		logger.Info().Int("attempts", attempts).Msg("webhook delivered")
+	}
+	n.complete(delivery, attempts, statusCode, err)
+}
+
+// post makes a single delivery attempt. Any response other than 2xx is an error.
+func (n *Notifier) post(ctx context.Context, delivery *model.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(n.config.Secret, timestamp, body))
+	req.Header.Set(EventHeader, delivery.Type)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// track records a new pending delivery, forgetting the oldest ones.
+func (n *Notifier) track(notification model.Notification, url string) *model.WebhookDelivery {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delivery := &model.WebhookDelivery{
+		ID:             uuid.NewString(),
+		NotificationID: notification.ID,
+		Type:           notification.Type,
+		URL:            url,
+		Status:         model.DeliveryPending,
+		StartedAt:      time.Now(),
+	}
+	n.deliveries = append(n.deliveries, delivery)
+	if len(n.deliveries) > maxDeliveries {
+		n.deliveries = n.deliveries[len(n.deliveries)-maxDeliveries:]
+	}
+	return delivery
+}
+
+// complete records the outcome of a delivery.
+func (n *Notifier) complete(delivery *model.WebhookDelivery, attempts, statusCode int, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	finishedAt := time.Now()
+	delivery.FinishedAt = &finishedAt
+	delivery.Attempts = attempts
+	delivery.StatusCode = statusCode
+	delivery.Status = model.DeliveryDelivered
+	if err != nil {
+		delivery.Status = model.DeliveryFailed
+		delivery.Error = err.Error()
+	}
+}
+
+// subscribes reports whether the webhook receives the notifications of the given type.
+func subscribes(endpoint configuration.WebhookConfig, notificationType string) bool {
+	if len(endpoint.Events) == 0 {
+		return true
+	}
+	for _, event := range endpoint.Events {
+		if event == notificationType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Notify_WhenReceiverFailsOnce(t *testing.T) {
+	var calls atomic.Int32
+	var received model.Notification
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("secret", r.Header.Get(TimestampHeader), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	notifier, _ := NewNotifier(configuration.WebhooksConfig{
+		Endpoints:  []configuration.WebhookConfig{{URL: receiver.URL}},
+		Secret:     "secret",
+		Timeout:    time.Second,
+		MaxRetries: 2,
+		RetryDelay: 10 * time.Millisecond,
+	})
+
+	err := notifier.Notify(context.Background(), model.Notification{ID: "n1", Type: model.NotificationPromoted, DocsType: "programs", IndexName: "programs-1"})
+	notifier.Wait()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "programs-1", received.IndexName)
+	deliveries := notifier.Deliveries()
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, model.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts, "Should be delivered on the retry")
+	assert.Equal(t, http.StatusNoContent, deliveries[0].StatusCode)
+}
+
+func Test_Notify_WhenReceiverKeepsFailing(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	notifier, _ := NewNotifier(configuration.WebhooksConfig{
+		Endpoints:  []configuration.WebhookConfig{{URL: receiver.URL}},
+		Secret:     "secret",
+		MaxRetries: 1,
+		RetryDelay: 10 * time.Millisecond,
+	})
+
+	_ = notifier.Notify(context.Background(), model.Notification{ID: "n1", Type: model.NotificationFailed})
+	notifier.Wait()
+
+	deliveries := notifier.Deliveries()
+	assert.Equal(t, model.DeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.NotEmpty(t, deliveries[0].Error)
+}
+
+func Test_Notify_WhenWebhookIsNotSubscribed(t *testing.T) {
+	notifier, _ := NewNotifier(configuration.WebhooksConfig{
+		Endpoints: []configuration.WebhookConfig{{URL: "http://localhost", Events: []string{model.NotificationPromoted}}},
+		Secret:    "secret",
+	})
+
+	_ = notifier.Notify(context.Background(), model.Notification{ID: "n1", Type: model.NotificationSucceeded})
+	notifier.Wait()
+
+	assert.Empty(t, notifier.Deliveries())
+}
+
+func Test_NewNotifier_WhenSecretIsBlank(t *testing.T) {
+	_, err := NewNotifier(configuration.WebhooksConfig{
+		Endpoints: []configuration.WebhookConfig{{URL: "http://localhost"}},
+		Secret:    " ",
+	})
+	assert.Error(t, err)
+
+	_, err = NewNotifier(configuration.WebhooksConfig{})
+	assert.NoError(t, err, "Should not require a secret without webhooks")
+}
+
+func Test_Drain_WhenDeliveryOutlastsShutdown(t *testing.T) {
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	defer close(release)
+	notifier, _ := NewNotifier(configuration.WebhooksConfig{
+		Endpoints: []configuration.WebhookConfig{{URL: receiver.URL}},
+		Secret:    "secret",
+		Timeout:   time.Second,
+	})
+	_ = notifier.Notify(context.Background(), model.Notification{ID: "n1", Type: model.NotificationPromoted})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := notifier.Drain(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, model.DeliveryPending, notifier.Deliveries()[0].Status)
+}
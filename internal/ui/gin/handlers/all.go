@@ -1,30 +1,30 @@
 package handlers
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
 )
 
-// All returns a Gin handler function that executes all indexations in background.
+// All returns a Gin handler function that starts an indexation job per docs type in background.
 //
 // @Summary Execute all indexation processes
 // @Description Execute all indexation processes
 // @Tags indexation-full
 // @ID index-all
 // @Produce json
-// @Success 200 {string} string "ok"
+// @Param dryRun query bool false "Report what the indexations would do without changing Elasticsearch"
+// @Success 200 {object} map[string]api.DryRunReport "dry run reports by docs type"
+// @Success 202 {array} model.Job "started jobs, or running ones for docs types already in progress"
 // @Failure 500 {object} pkg.ErrorJSON
 // @Router /private/indexation/all [post]
 //
 // @Security Bearer-APIKey || Bearer-JWT
 func (handler indexationHandler) All() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		go func() {
-			ctx := context.WithValue(c, "goroutine", "Indexation-All")
-			err := handler.indexerApi.All(ctx)
-			if err != nil {
-				return
-			}
-		}()
+		if isDryRun(c) {
+			handler.dryRun(c, api.DocsTypes...)
+			return
+		}
+		handler.start(c, api.DocsTypes...)
 	}
 }
@@ -1,30 +1,31 @@
 package handlers
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
 )
 
-// Blocks returns a Gin handler function that executes a block indexation in background.
+// Blocks returns a Gin handler function that starts a block indexation job in background.
 //
 // @Summary Execute block indexation process
 // @Description Execute block indexation process
 // @Tags indexation-full
 // @ID index-block
 // @Produce json
-// @Success 200 {string} string "ok"
+// @Param dryRun query bool false "Report what the indexation would do without changing Elasticsearch"
+// @Success 200 {object} api.DryRunReport "dry run report"
+// @Success 202 {object} model.Job "started job"
+// @Failure 409 {object} pkg.ErrorJSON
 // @Failure 500 {object} pkg.ErrorJSON
 // @Router /private/indexation/blocks [post]
 //
 // @Security Bearer-APIKey || Bearer-JWT
 func (handler indexationHandler) Blocks() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		go func() {
-			ctx := context.WithValue(c, "goroutine", "Indexation-Blocks")
-			err := handler.indexerApi.Blocks(ctx)
-			if err != nil {
-				return
-			}
-		}()
+		if isDryRun(c) {
+			handler.dryRun(c, api.Blocks)
+			return
+		}
+		handler.start(c, api.Blocks)
 	}
 }
@@ -1,30 +1,31 @@
 package handlers
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
 )
 
-// Categories returns a Gin handler function that executes a category indexation in background.
+// Categories returns a Gin handler function that starts a category indexation job in background.
 //
 // @Summary Execute category indexation process
 // @Description Execute category indexation process
 // @Tags indexation-full
 // @ID index-category
 // @Produce json
-// @Success 200 {string} string "ok"
+// @Param dryRun query bool false "Report what the indexation would do without changing Elasticsearch"
+// @Success 200 {object} api.DryRunReport "dry run report"
+// @Success 202 {object} model.Job "started job"
+// @Failure 409 {object} pkg.ErrorJSON
 // @Failure 500 {object} pkg.ErrorJSON
 // @Router /private/indexation/categories [post]
 //
 // @Security Bearer-APIKey || Bearer-JWT
 func (handler indexationHandler) Categories() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		go func() {
-			ctx := context.WithValue(c, "goroutine", "Indexation-Categories")
-			err := handler.indexerApi.Categories(ctx)
-			if err != nil {
-				return
-			}
-		}()
+		if isDryRun(c) {
+			handler.dryRun(c, api.Cats)
+			return
+		}
+		handler.start(c, api.Cats)
 	}
 }
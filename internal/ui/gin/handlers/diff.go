@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// Diff returns a Gin handler function that compares two generations of the index of a docs type.
+//
+// @Summary Diff index generations
+// @Description Compare two generations of the index of a docs type by document ID and content hash,
+// @Description and report the added, removed and modified documents with the changed fields of a sample of them
+// @Tags indexation-consistency
+// @ID diff-index-generations
+// @Produce json
+// @Param type path string true "Docs type (categories, tags, walls, blocks, programs, episodes or medias)"
+// @Param from query string false "Generation compared from (latest, previous or in-progress, default previous)"
+// @Param to query string false "Generation compared to (latest, previous or in-progress, default latest)"
+// @Param sample query int false "Number of modified documents detailed (default 10, at most 100)"
+// @Success 200 {object} model.GenerationDiff
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 404 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /private/indexation/{type}/diff [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) Diff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sample, err := intQuery(c, "sample")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+
+		diff, err := handler.indexerApi.Diff(c.Request.Context(), docsTypeParam(c), c.DefaultQuery("from", "previous"), c.DefaultQuery("to", "latest"), sample)
+		switch {
+		case errors.Is(err, api.ErrUnknownGeneration):
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+		case errors.Is(err, api.ErrUnknownDocsType), errors.Is(err, api.ErrNoGeneration):
+			c.JSON(http.StatusNotFound, pkg.ErrorJSON{Error: err.Error()})
+		case err != nil:
+			log.Error().Err(err).Str("docsType", c.Param("type")).Msg("could not diff index generations")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusOK, diff)
+		}
+	}
+}
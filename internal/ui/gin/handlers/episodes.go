@@ -1,30 +1,31 @@
 package handlers
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
 )
 
-// Episodes returns a Gin handler function that executes a episode indexation in background.
+// Episodes returns a Gin handler function that starts an episode indexation job in background.
 //
 // @Summary Execute episode indexation process
 // @Description Execute episode indexation process
 // @Tags indexation-full
 // @ID index-episode
 // @Produce json
-// @Success 200 {string} string "ok"
+// @Param dryRun query bool false "Report what the indexation would do without changing Elasticsearch"
+// @Success 200 {object} api.DryRunReport "dry run report"
+// @Success 202 {object} model.Job "started job"
+// @Failure 409 {object} pkg.ErrorJSON
 // @Failure 500 {object} pkg.ErrorJSON
 // @Router /private/indexation/episodes [post]
 //
 // @Security Bearer-APIKey || Bearer-JWT
 func (handler indexationHandler) Episodes() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		go func() {
-			ctx := context.WithValue(c, "goroutine", "Indexation-Episodes")
-			err := handler.indexerApi.Episodes(ctx)
-			if err != nil {
-				return
-			}
-		}()
+		if isDryRun(c) {
+			handler.dryRun(c, api.Episodes)
+			return
+		}
+		handler.start(c, api.Episodes)
 	}
 }
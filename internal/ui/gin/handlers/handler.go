@@ -2,8 +2,14 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
 )
 
 // Indexation represents the interface for managing indexation.
@@ -16,6 +22,16 @@ type Indexation interface {
 	Episodes() gin.HandlerFunc
 	Medias() gin.HandlerFunc
 	All() gin.HandlerFunc
+	Jobs() gin.HandlerFunc
+	Job() gin.HandlerFunc
+	CancelJob() gin.HandlerFunc
+	JobEvents() gin.HandlerFunc
+	History() gin.HandlerFunc
+	Verify() gin.HandlerFunc
+	Diff() gin.HandlerFunc
+	Integrity() gin.HandlerFunc
+	SearchTerms() gin.HandlerFunc
+	UpdateSearchTerms() gin.HandlerFunc
 }
 
 // indexationHandler is an implementation of the Indexation interface.
@@ -29,3 +45,65 @@ func NewIndexationHandler(indexerApi api.Indexer) Indexation {
 		indexerApi: indexerApi,
 	}
 }
+
+// docsTypeParam returns the docs type named by the type path parameter, which follows the indexation routes:
+// categories stands for the cats docs type.
+func docsTypeParam(c *gin.Context) string {
+	if docsType := c.Param("type"); docsType != "categories" {
+		return docsType
+	}
+	return api.Cats
+}
+
+// isDryRun reports whether the request asks for a dry run through the dryRun query parameter.
+func isDryRun(c *gin.Context) bool {
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	return dryRun
+}
+
+// dryRun responds with the dry run reports of the given docs types.
+// A single docs type is answered with its report, several ones with a report per docs type.
+func (handler indexationHandler) dryRun(c *gin.Context, docsTypes ...string) {
+	reports := make(map[string]api.DryRunReport, len(docsTypes))
+	for _, docsType := range docsTypes {
+		report, err := handler.indexerApi.DryRun(c, docsType)
+		if err != nil {
+			log.Error().Err(err).Str("docsType", docsType).Msg("dry run failed")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		reports[docsType] = report
+	}
+
+	if len(docsTypes) == 1 {
+		c.JSON(http.StatusOK, reports[docsTypes[0]])
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// start starts a full indexation job per docs type, carrying the trace identifier, logger and caller of the request.
+// A single docs type is answered with its job, or a conflict if it is already being indexed.
+// Several docs types are answered with their jobs, the running one being reported for docs types already in progress.
+func (handler indexationHandler) start(c *gin.Context, docsTypes ...string) {
+	jobs := make([]model.Job, 0, len(docsTypes))
+	for _, docsType := range docsTypes {
+		job, err := handler.indexerApi.Start(c.Request.Context(), api.JobRequest{DocsType: docsType, Mode: api.ModeFull, Trigger: model.TriggerAPI})
+		if err != nil && !errors.Is(err, api.ErrJobInProgress) {
+			log.Error().Err(err).Str("docsType", docsType).Msg("could not start indexation")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		if err != nil && len(docsTypes) == 1 {
+			c.JSON(http.StatusConflict, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		jobs = append(jobs, job)
+	}
+
+	if len(docsTypes) == 1 {
+		c.JSON(http.StatusAccepted, jobs[0])
+		return
+	}
+	c.JSON(http.StatusAccepted, jobs)
+}
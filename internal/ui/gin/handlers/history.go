@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// History returns a Gin handler function that queries the history of the indexation jobs.
+//
+// @Summary Query indexation history
+// @Description Query the recorded indexation jobs, newest first, with their trigger, caller, index, counters and outcome
+// @Tags indexation-jobs
+// @ID query-history
+// @Produce json
+// @Param docsType query string false "Docs type of the jobs"
+// @Param mode query string false "Indexation mode of the jobs (full, delta or reconcile)"
+// @Param status query string false "Status of the jobs (running, succeeded, failed or cancelled)"
+// @Param trigger query string false "Source that triggered the jobs (api or scheduler)"
+// @Param caller query string false "Identity of the caller that requested the jobs"
+// @Param from query string false "Lower bound of the start time of the jobs (RFC 3339)"
+// @Param to query string false "Upper bound, excluded, of the start time of the jobs (RFC 3339)"
+// @Param limit query int false "Maximum number of jobs returned (default 20, at most 100)"
+// @Param offset query int false "Number of matching jobs skipped"
+// @Success 200 {object} model.JobPage
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Failure 501 {object} pkg.ErrorJSON
+// @Router /private/indexation/history [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) History() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := historyFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+
+		page, err := handler.indexerApi.History(c.Request.Context(), filter)
+		if errors.Is(err, api.ErrNoHistory) {
+			c.JSON(http.StatusNotImplemented, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("could not query indexation history")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// historyFilter reads the filter of a history query from the query parameters.
+func historyFilter(c *gin.Context) (model.JobFilter, error) {
+	filter := model.JobFilter{
+		DocsType: c.Query("docsType"),
+		Mode:     c.Query("mode"),
+		Status:   c.Query("status"),
+		Trigger:  c.Query("trigger"),
+		Caller:   c.Query("caller"),
+	}
+
+	var err error
+	if filter.From, err = timeQuery(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = timeQuery(c, "to"); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = intQuery(c, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.Offset, err = intQuery(c, "offset"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// timeQuery reads an optional RFC 3339 time from a query parameter.
+func timeQuery(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: expected an RFC 3339 time", name)
+	}
+	return parsed, nil
+}
+
+// intQuery reads an optional non-negative integer from a query parameter.
+func intQuery(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s: expected a non-negative integer", name)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// Integrity returns a Gin handler function that reports the entities breaking the references between entities.
+//
+// @Summary Referential integrity report
+// @Description Check the references between the entities read from MySQL, as the indexations read them, and report
+// @Description the episodes without program, the medias without episode, the categories without parent
+// @Description and the programs without episodes. The report is returned as JSON, or as CSV with one row per orphan.
+// @Tags indexation-consistency
+// @ID integrity-report
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "Format of the report (json or csv, default json)"
+// @Success 200 {object} model.IntegrityReport
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /private/indexation/integrity [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) Integrity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" {
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: "invalid format parameter"})
+			return
+		}
+
+		report, err := handler.indexerApi.Integrity(c.Request.Context())
+		if err != nil {
+			log.Error().Err(err).Msg("could not check referential integrity")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		if format == "json" {
+			c.JSON(http.StatusOK, report)
+			return
+		}
+
+		data, err := integrityCSV(report)
+		if err != nil {
+			log.Error().Err(err).Msg("could not write referential integrity report")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="integrity.csv"`)
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+	}
+}
+
+// integrityCSV writes the orphans of the report as CSV, one row per orphan along with the check that found it.
+func integrityCSV(report model.IntegrityReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	records := [][]string{{"check", "docsType", "id", "referenceId"}}
+	for _, check := range report.Checks {
+		for _, orphan := range check.Orphans {
+			records = append(records, []string{check.Name, check.DocsType, orphan.ID, orphan.ReferenceID})
+		}
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
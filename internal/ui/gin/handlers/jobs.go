@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"io"
+	"net/http"
+)
+
+// Jobs returns a Gin handler function that lists the running and recently finished indexation jobs.
+//
+// @Summary List indexation jobs
+// @Description List the running and recently finished indexation jobs, newest first
+// @Tags indexation-jobs
+// @ID list-jobs
+// @Produce json
+// @Success 200 {array} model.Job
+// @Router /private/indexation/jobs [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) Jobs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, handler.indexerApi.Jobs())
+	}
+}
+
+// Job returns a Gin handler function that gets an indexation job.
+//
+// @Summary Get indexation job
+// @Description Get an indexation job by its identifier
+// @Tags indexation-jobs
+// @ID get-job
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.Job
+// @Failure 404 {object} pkg.ErrorJSON
+// @Router /private/indexation/jobs/{id} [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) Job() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := handler.indexerApi.Job(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+// CancelJob returns a Gin handler function that cancels a running indexation job.
+//
+// @Summary Cancel indexation job
+// @Description Cancel a running indexation job, its partial index is deleted and the job ends in the cancelled state
+// @Tags indexation-jobs
+// @ID cancel-job
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 202 {object} model.Job
+// @Failure 404 {object} pkg.ErrorJSON
+// @Failure 409 {object} pkg.ErrorJSON
+// @Router /private/indexation/jobs/{id}/cancel [post]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) CancelJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := handler.indexerApi.Cancel(c.Param("id"))
+		switch {
+		case errors.Is(err, api.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, pkg.ErrorJSON{Error: err.Error()})
+		case errors.Is(err, api.ErrJobFinished):
+			c.JSON(http.StatusConflict, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusAccepted, job)
+		}
+	}
+}
+
+// JobEvents returns a Gin handler function that streams the progress of an indexation job as Server-Sent Events.
+//
+// @Summary Stream indexation job events
+// @Description Stream the progress of an indexation job as Server-Sent Events: a snapshot of the job first,
+// @Description then an event per phase change and counters update, and a final event once the job is finished
+// @Tags indexation-jobs
+// @ID stream-job-events
+// @Produce text/event-stream
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.JobEvent
+// @Failure 404 {object} pkg.ErrorJSON
+// @Router /private/indexation/jobs/{id}/events [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) JobEvents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events, unsubscribe, err := handler.indexerApi.Subscribe(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+		defer unsubscribe()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case event, ok := <-events:
+				if !ok {
+					return false
+				}
+				c.SSEvent(event.Type, event)
+				return event.Type != model.EventFinished
+			}
+		})
+	}
+}
@@ -1,30 +1,31 @@
 package handlers
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
 )
 
-// Medias returns a Gin handler function that executes a media indexation in background.
+// Medias returns a Gin handler function that starts a media indexation job in background.
 //
 // @Summary Execute media indexation process
 // @Description Execute media indexation process
 // @Tags indexation-full
 // @ID index-media
 // @Produce json
-// @Success 200 {string} string "ok"
+// @Param dryRun query bool false "Report what the indexation would do without changing Elasticsearch"
+// @Success 200 {object} api.DryRunReport "dry run report"
+// @Success 202 {object} model.Job "started job"
+// @Failure 409 {object} pkg.ErrorJSON
 // @Failure 500 {object} pkg.ErrorJSON
 // @Router /private/indexation/medias [post]
 //
 // @Security Bearer-APIKey || Bearer-JWT
 func (handler indexationHandler) Medias() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		go func() {
-			ctx := context.WithValue(c, "goroutine", "Indexation-Medias")
-			err := handler.indexerApi.Medias(ctx)
-			if err != nil {
-				return
-			}
-		}()
+		if isDryRun(c) {
+			handler.dryRun(c, api.Medias)
+			return
+		}
+		handler.start(c, api.Medias)
 	}
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"net/http"
+)
+
+// Scheduler represents the periodic reindexing scheduler driven by the handlers.
+type Scheduler interface {
+	Schedule() model.Schedule
+	SetEnabled(enabled bool)
+}
+
+// Scheduling represents the interface for managing the indexation scheduler.
+type Scheduling interface {
+	Get() gin.HandlerFunc
+	Enable() gin.HandlerFunc
+	Disable() gin.HandlerFunc
+}
+
+// schedulingHandler is an implementation of the Scheduling interface.
+type schedulingHandler struct {
+	scheduler Scheduler
+}
+
+// NewSchedulingHandler creates a new instance of Scheduling interface.
+func NewSchedulingHandler(scheduler Scheduler) Scheduling {
+	return &schedulingHandler{
+		scheduler: scheduler,
+	}
+}
+
+// Get returns a Gin handler function that reports the scheduled indexations and their next run times.
+//
+// @Summary Get indexation schedule
+// @Description Get the scheduled indexations and their next run times
+// @Tags indexation-schedule
+// @ID get-schedule
+// @Produce json
+// @Success 200 {object} model.Schedule
+// @Router /private/indexation/schedule [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler schedulingHandler) Get() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, handler.scheduler.Schedule())
+	}
+}
+
+// Enable returns a Gin handler function that enables the scheduled indexations.
+//
+// @Summary Enable indexation schedule
+// @Description Enable the scheduled indexations
+// @Tags indexation-schedule
+// @ID enable-schedule
+// @Produce json
+// @Success 200 {object} model.Schedule
+// @Router /private/indexation/schedule/enable [post]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler schedulingHandler) Enable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler.scheduler.SetEnabled(true)
+		c.JSON(http.StatusOK, handler.scheduler.Schedule())
+	}
+}
+
+// Disable returns a Gin handler function that disables the scheduled indexations.
+//
+// @Summary Disable indexation schedule
+// @Description Disable the scheduled indexations, runs already executing are not interrupted
+// @Tags indexation-schedule
+// @ID disable-schedule
+// @Produce json
+// @Success 200 {object} model.Schedule
+// @Router /private/indexation/schedule/disable [post]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler schedulingHandler) Disable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler.scheduler.SetEnabled(false)
+		c.JSON(http.StatusOK, handler.scheduler.Schedule())
+	}
+}
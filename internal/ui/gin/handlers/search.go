@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Search represents the interface for searching the latest indexes.
+type Search interface {
+	Federate() gin.HandlerFunc
+	Suggest() gin.HandlerFunc
+	Programs() gin.HandlerFunc
+	Episodes() gin.HandlerFunc
+	Medias() gin.HandlerFunc
+}
+
+// searchHandler is an implementation of the Search interface.
+type searchHandler struct {
+	searchApi api.Search
+}
+
+// NewSearchHandler creates a new instance of Search interface.
+func NewSearchHandler(searchApi api.Search) Search {
+	return &searchHandler{
+		searchApi: searchApi,
+	}
+}
+
+// Federate returns a Gin handler function that searches the latest indexes of several docs types at once.
+//
+// @Summary Search all docs types
+// @Description Search the programs, episodes, categories, tags and walls of the latest indexes in a single request.
+// @Description The best documents are returned per docs type along with the number of matching documents,
+// @Description and blended in top hits ranked by their score multiplied by the configured boost of their docs type.
+// @Description The names and descriptions are also searched in the languages configured for the docs types,
+// @Description in the LocalizedName.<language> and LocalizedDescription.<language> fields (e.g. LocalizedName.fr).
+// @Tags search
+// @ID federated-search
+// @Produce json
+// @Param q query string true "Words searched"
+// @Param types query string false "Comma-separated docs types to search (programs, episodes, categories, tags or walls, default all)"
+// @Param size query int false "Maximum number of documents per docs type (default configured, at most 100)"
+// @Success 200 {object} model.FederatedResult
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /search [get]
+func (handler searchHandler) Federate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		size, err := intQuery(c, "size")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+
+		result, err := handler.searchApi.Federate(c.Request.Context(), c.Query("q"), docsTypesQuery(c, "types"), size)
+		switch {
+		case errors.Is(err, api.ErrEmptyQuery), errors.Is(err, api.ErrUnknownDocsType):
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+		case err != nil:
+			log.Error().Err(err).Msg("could not search documents")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusOK, result)
+		}
+	}
+}
+
+// Suggest returns a Gin handler function that suggests the documents whose name completes the words typed so far.
+//
+// @Summary Suggest as you type
+// @Description Suggest the programs, episodes, tags and categories of the latest indexes whose name completes the query,
+// @Description the last word being matched as a prefix. The suggestions are grouped by docs type, best first.
+// @Tags search
+// @ID suggest
+// @Produce json
+// @Param q query string true "Words typed so far"
+// @Param types query string false "Comma-separated docs types to suggest (programs, episodes, tags or categories, default all)"
+// @Param size query int false "Maximum number of suggestions per docs type (default 5, at most 20)"
+// @Success 200 {object} model.Suggestions
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /search/suggest [get]
+func (handler searchHandler) Suggest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		size, err := intQuery(c, "size")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+
+		suggestions, err := handler.searchApi.Suggest(c.Request.Context(), c.Query("q"), docsTypesQuery(c, "types"), size)
+		switch {
+		case errors.Is(err, api.ErrEmptyQuery), errors.Is(err, api.ErrUnknownDocsType):
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+		case err != nil:
+			log.Error().Err(err).Msg("could not suggest documents")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusOK, suggestions)
+		}
+	}
+}
+
+// Programs returns a Gin handler function that searches the programs of the latest index.
+//
+// @Summary Search programs
+// @Description Search the programs of the latest index by name and description, best matches first by default.
+// @Description The names and descriptions are also searched in the languages configured for the programs,
+// @Description in the LocalizedName.<language> and LocalizedDescription.<language> fields (e.g. LocalizedName.fr).
+// @Description The programs are not linked to tags nor categories, filtering by tag or category is rejected.
+// @Tags search
+// @ID search-programs
+// @Produce json
+// @Param q query string false "Words searched, every program matches without"
+// @Param sort query string false "Sort of the programs (relevance, name or episode-count, default relevance)"
+// @Param order query string false "Order of the sort (asc or desc, default desc for relevance and asc otherwise)"
+// @Param highlight query bool false "Return the matching fragments of the names and descriptions"
+// @Param limit query int false "Maximum number of programs returned (default 20, at most 100)"
+// @Param offset query int false "Number of matching programs skipped"
+// @Success 200 {object} model.SearchResult
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /search/programs [get]
+func (handler searchHandler) Programs() gin.HandlerFunc {
+	return handler.search(api.Programs)
+}
+
+// Episodes returns a Gin handler function that searches the episodes of the latest index.
+//
+// @Summary Search episodes
+// @Description Search the episodes of the latest index by name and description, best matches first by default.
+// @Description The names and descriptions are also searched in the languages configured for the episodes,
+// @Description in the LocalizedName.<language> and LocalizedDescription.<language> fields (e.g. LocalizedName.fr).
+// @Description The episodes are not linked to tags nor categories, filtering by tag or category is rejected.
+// @Tags search
+// @ID search-episodes
+// @Produce json
+// @Param q query string false "Words searched, every episode matches without"
+// @Param program query string false "Identifier of the program of the episodes"
+// @Param kind query string false "Kind of the media of the episodes"
+// @Param sort query string false "Sort of the episodes (relevance, name or position, default relevance)"
+// @Param order query string false "Order of the sort (asc or desc, default desc for relevance and asc otherwise)"
+// @Param highlight query bool false "Return the matching fragments of the names and descriptions"
+// @Param limit query int false "Maximum number of episodes returned (default 20, at most 100)"
+// @Param offset query int false "Number of matching episodes skipped"
+// @Success 200 {object} model.SearchResult
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /search/episodes [get]
+func (handler searchHandler) Episodes() gin.HandlerFunc {
+	return handler.search(api.Episodes)
+}
+
+// Medias returns a Gin handler function that searches the medias of the latest index.
+//
+// @Summary Search medias
+// @Description Search the medias of the latest index by direct link and kind.
+// @Tags search
+// @ID search-medias
+// @Produce json
+// @Param q query string false "Words searched in the direct links, every media matches without"
+// @Param kind query string false "Kind of the medias"
+// @Param highlight query bool false "Return the matching fragments of the direct links"
+// @Param limit query int false "Maximum number of medias returned (default 20, at most 100)"
+// @Param offset query int false "Number of matching medias skipped"
+// @Success 200 {object} model.SearchResult
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /search/media [get]
+func (handler searchHandler) Medias() gin.HandlerFunc {
+	return handler.search(api.Medias)
+}
+
+// search returns a Gin handler function that searches the documents of the docs type in its latest index.
+func (handler searchHandler) search(docsType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := searchQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+			return
+		}
+
+		result, err := handler.searchApi.Search(c.Request.Context(), docsType, query)
+		switch {
+		case errors.Is(err, api.ErrInvalidSearch):
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+		case err != nil:
+			log.Error().Err(err).Str("docsType", docsType).Msg("could not search documents")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusOK, result)
+		}
+	}
+}
+
+// searchQuery reads a search query from the query parameters.
+// The tag and category filters are rejected rather than ignored: the programs and episodes reference neither
+// tags nor categories in the database, their documents cannot be filtered by them.
+func searchQuery(c *gin.Context) (model.SearchQuery, error) {
+	for _, name := range []string{"tag", "category"} {
+		if c.Query(name) != "" {
+			return model.SearchQuery{}, fmt.Errorf("unsupported %s parameter: the documents are not linked to tags nor categories", name)
+		}
+	}
+	query := model.SearchQuery{
+		Text:      c.Query("q"),
+		ProgramID: c.Query("program"),
+		Kind:      c.Query("kind"),
+		Sort:      c.Query("sort"),
+		Order:     c.Query("order"),
+	}
+
+	var err error
+	if value := c.Query("highlight"); value != "" {
+		if query.Highlight, err = strconv.ParseBool(value); err != nil {
+			return query, errors.New("invalid highlight parameter")
+		}
+	}
+	if query.Limit, err = intQuery(c, "limit"); err != nil {
+		return query, err
+	}
+	if query.Offset, err = intQuery(c, "offset"); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// docsTypesQuery reads an optional comma-separated list of docs types from a query parameter,
+// categories standing for the cats docs type as in the indexation routes.
+func docsTypesQuery(c *gin.Context, name string) []string {
+	var docsTypes []string
+	for _, docsType := range strings.Split(c.Query(name), ",") {
+		switch docsType = strings.TrimSpace(docsType); docsType {
+		case "":
+		case "categories":
+			docsTypes = append(docsTypes, api.Cats)
+		default:
+			docsTypes = append(docsTypes, docsType)
+		}
+	}
+	return docsTypes
+}
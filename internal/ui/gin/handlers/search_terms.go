@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+)
+
+// SearchTerms returns a Gin handler function that returns the synonyms and stopwords of the search analyzers.
+//
+// @Summary Get search synonyms
+// @Description Get the synonym rules and stopwords applied by the analyzers of the indexes
+// @Tags search-terms
+// @ID get-search-synonyms
+// @Produce json
+// @Success 200 {object} model.SearchTerms
+// @Failure 500 {object} pkg.ErrorJSON
+// @Failure 501 {object} pkg.ErrorJSON
+// @Router /private/search/synonyms [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) SearchTerms() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		terms, err := handler.indexerApi.SearchTerms(c.Request.Context())
+		switch {
+		case errors.Is(err, api.ErrNoSearchTerms):
+			c.JSON(http.StatusNotImplemented, pkg.ErrorJSON{Error: err.Error()})
+		case err != nil:
+			log.Error().Err(err).Msg("could not get search terms")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusOK, terms)
+		}
+	}
+}
+
+// UpdateSearchTerms returns a Gin handler function that replaces the synonyms and stopwords of the search analyzers.
+//
+// @Summary Update search synonyms
+// @Description Replace the synonym rules, e.g. "pod, podcast" or "actu => actualités", and the stopwords.
+// @Description They apply to the indexes created from now on. On request, the synonyms also apply at once to the
+// @Description searches of the latest, previous and in-progress indexes, whose search analyzers are reloaded.
+// @Tags search-terms
+// @ID update-search-synonyms
+// @Accept json
+// @Produce json
+// @Param terms body model.SearchTerms true "Synonym rules and stopwords"
+// @Param apply query bool false "Apply the synonyms to the live indexes without rebuilding them"
+// @Success 200 {object} model.SearchTermsUpdate
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Failure 501 {object} pkg.ErrorJSON
+// @Router /private/search/synonyms [post]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) UpdateSearchTerms() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var terms model.SearchTerms
+		if err := c.ShouldBindJSON(&terms); err != nil {
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: "invalid search terms: " + err.Error()})
+			return
+		}
+		var reload bool
+		if value := c.Query("apply"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: "invalid apply parameter"})
+				return
+			}
+			reload = parsed
+		}
+
+		update, err := handler.indexerApi.UpdateSearchTerms(c.Request.Context(), terms, reload)
+		switch {
+		case errors.Is(err, api.ErrNoSearchTerms):
+			c.JSON(http.StatusNotImplemented, pkg.ErrorJSON{Error: err.Error()})
+		case errors.Is(err, api.ErrInvalidSearchTerms):
+			c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: err.Error()})
+		case err != nil:
+			log.Error().Err(err).Msg("could not update search terms")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusOK, update)
+		}
+	}
+}
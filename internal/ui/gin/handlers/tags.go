@@ -1,30 +1,31 @@
 package handlers
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
 )
 
-// Tags returns a Gin handler function that executes a tag indexation in background.
+// Tags returns a Gin handler function that starts a tag indexation job in background.
 //
 // @Summary Execute tag indexation process
 // @Description Execute tag indexation process
 // @Tags indexation-full
 // @ID index-tag
 // @Produce json
-// @Success 200 {string} string "ok"
+// @Param dryRun query bool false "Report what the indexation would do without changing Elasticsearch"
+// @Success 200 {object} api.DryRunReport "dry run report"
+// @Success 202 {object} model.Job "started job"
+// @Failure 409 {object} pkg.ErrorJSON
 // @Failure 500 {object} pkg.ErrorJSON
 // @Router /private/indexation/tags [post]
 //
 // @Security Bearer-APIKey || Bearer-JWT
 func (handler indexationHandler) Tags() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		go func() {
-			ctx := context.WithValue(c, "goroutine", "Indexation-Tags")
-			err := handler.indexerApi.Tags(ctx)
-			if err != nil {
-				return
-			}
-		}()
+		if isDryRun(c) {
+			handler.dryRun(c, api.Tags)
+			return
+		}
+		handler.start(c, api.Tags)
 	}
 }
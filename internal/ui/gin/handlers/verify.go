@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/khedhrije/podcaster-indexer-api/pkg"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+)
+
+// Verify returns a Gin handler function that checks the consistency of the latest index of a docs type with the database.
+//
+// @Summary Verify index consistency
+// @Description Compare the identifiers, and optionally the content, of the entities of a docs type in MySQL
+// @Description with the documents of its latest index, and report the missing, extra and differing documents.
+// @Description On request, missing documents are indexed, differing documents are overwritten whatever their version
+// @Description and extra documents are removed; the documents that could not be written are listed in repair.notRepaired.
+// @Tags indexation-consistency
+// @ID verify-index
+// @Produce json
+// @Param type path string true "Docs type (categories, tags, walls, blocks, programs, episodes or medias)"
+// @Param content query bool false "Compare the content of the documents, not only their identifiers"
+// @Param repair query bool false "Repair the differences"
+// @Success 200 {object} model.ConsistencyReport
+// @Failure 400 {object} pkg.ErrorJSON
+// @Failure 404 {object} pkg.ErrorJSON
+// @Failure 409 {object} pkg.ErrorJSON
+// @Failure 500 {object} pkg.ErrorJSON
+// @Router /private/indexation/{type}/verify [post]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler indexationHandler) Verify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var options api.VerifyOptions
+		for name, option := range map[string]*bool{"content": &options.Content, "repair": &options.Repair} {
+			if value := c.Query(name); value != "" {
+				parsed, err := strconv.ParseBool(value)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, pkg.ErrorJSON{Error: "invalid " + name + " parameter"})
+					return
+				}
+				*option = parsed
+			}
+		}
+
+		report, err := handler.indexerApi.Verify(c.Request.Context(), docsTypeParam(c), options)
+		switch {
+		case errors.Is(err, api.ErrUnknownDocsType):
+			c.JSON(http.StatusNotFound, pkg.ErrorJSON{Error: err.Error()})
+		case errors.Is(err, api.ErrNoLatestIndex):
+			c.JSON(http.StatusConflict, pkg.ErrorJSON{Error: err.Error()})
+		case err != nil:
+			log.Error().Err(err).Str("docsType", c.Param("type")).Msg("could not verify index consistency")
+			c.JSON(http.StatusInternalServerError, pkg.ErrorJSON{Error: err.Error()})
+		default:
+			c.JSON(http.StatusOK, report)
+		}
+	}
+}
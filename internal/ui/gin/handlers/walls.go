@@ -1,30 +1,31 @@
 package handlers
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
 )
 
-// Walls returns a Gin handler function that executes a wall indexation in background.
+// Walls returns a Gin handler function that starts a wall indexation job in background.
 //
 // @Summary Execute wall indexation process
 // @Description Execute wall indexation process
 // @Tags indexation-full
 // @ID index-wall
 // @Produce json
-// @Success 200 {string} string "ok"
+// @Param dryRun query bool false "Report what the indexation would do without changing Elasticsearch"
+// @Success 200 {object} api.DryRunReport "dry run report"
+// @Success 202 {object} model.Job "started job"
+// @Failure 409 {object} pkg.ErrorJSON
 // @Failure 500 {object} pkg.ErrorJSON
 // @Router /private/indexation/walls [post]
 //
 // @Security Bearer-APIKey || Bearer-JWT
 func (handler indexationHandler) Walls() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		go func() {
-			ctx := context.WithValue(c, "goroutine", "Indexation-Walls")
-			err := handler.indexerApi.Walls(ctx)
-			if err != nil {
-				return
-			}
-		}()
+		if isDryRun(c) {
+			handler.dryRun(c, api.Walls)
+			return
+		}
+		handler.start(c, api.Walls)
 	}
 }
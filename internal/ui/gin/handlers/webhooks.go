@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/model"
+	"net/http"
+)
+
+// DeliveryLog represents the log of the notifications delivered to the webhooks.
+type DeliveryLog interface {
+	Deliveries() []model.WebhookDelivery
+}
+
+// Webhooks represents the interface for following the outbound webhooks.
+type Webhooks interface {
+	Deliveries() gin.HandlerFunc
+}
+
+// webhooksHandler is an implementation of the Webhooks interface.
+type webhooksHandler struct {
+	deliveryLog DeliveryLog
+}
+
+// NewWebhooksHandler creates a new instance of Webhooks interface.
+func NewWebhooksHandler(deliveryLog DeliveryLog) Webhooks {
+	return &webhooksHandler{
+		deliveryLog: deliveryLog,
+	}
+}
+
+// Deliveries returns a Gin handler function that lists the most recent webhook deliveries.
+//
+// @Summary List webhook deliveries
+// @Description List the most recent deliveries of indexation notifications to the webhooks, newest first
+// @Tags indexation-webhooks
+// @ID list-webhook-deliveries
+// @Produce json
+// @Success 200 {array} model.WebhookDelivery
+// @Router /private/indexation/webhooks/deliveries [get]
+//
+// @Security Bearer-APIKey || Bearer-JWT
+func (handler webhooksHandler) Deliveries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, handler.deliveryLog.Deliveries())
+	}
+}
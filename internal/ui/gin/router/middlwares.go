@@ -1,8 +1,15 @@
 package router
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/khedhrije/podcaster-indexer-api/internal/configuration"
+	"github.com/khedhrije/podcaster-indexer-api/internal/domain/api"
+	"github.com/rs/zerolog/log"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -13,8 +20,27 @@ import (
 const (
 	validationEndpoint = "/private/token/validate"
 	refreshEndpoint    = "/private/token/refresh"
+	traceIDHeader      = "X-Request-ID"
 )
 
+// RequestContextMiddleware creates a Gin middleware that attaches a trace identifier and a logger to the request context.
+// The trace identifier is read from the X-Request-ID header, generated if missing, and echoed in the response.
+func RequestContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(traceIDHeader)
+		if traceID == "" {
+			traceID = uuid.NewString()
+		}
+		c.Header(traceIDHeader, traceID)
+
+		logger := log.With().Str("traceId", traceID).Str("path", c.FullPath()).Logger()
+		ctx := logger.WithContext(api.WithTraceID(c.Request.Context(), traceID))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
 // TokenValidatorMiddleware creates a Gin middleware that validates a token by calling an external endpoint.
 func TokenValidatorMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -71,11 +97,28 @@ func TokenValidatorMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Token is valid, proceed to the next handler
+		// Token is valid, record the caller and proceed to the next handler
+		c.Request = c.Request.WithContext(api.WithCaller(c.Request.Context(), callerIdentity(token)))
 		c.Next()
 	}
 }
 
+// callerIdentity returns the identity of the owner of a validated bearer token: the subject of a JWT,
+// or a fingerprint of an API key so that the key itself is never recorded.
+func callerIdentity(token string) string {
+	if parts := strings.Split(token, "."); len(parts) == 3 {
+		var claims struct {
+			Subject string `json:"sub"`
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err == nil && json.Unmarshal(payload, &claims) == nil && claims.Subject != "" {
+			return claims.Subject
+		}
+	}
+	fingerprint := sha256.Sum256([]byte(token))
+	return "apikey:" + hex.EncodeToString(fingerprint[:6])
+}
+
 // TokenRefresherMiddleware creates a Gin middleware that refreshes a token by calling an external endpoint.
 func TokenRefresherMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -14,7 +14,7 @@ import (
 )
 
 // CreateRouter sets up and returns a new Gin router with the defined routes.
-func CreateRouter(handler handlers.Indexation) *gin.Engine {
+func CreateRouter(handler handlers.Indexation, schedulingHandler handlers.Scheduling, webhooksHandler handlers.Webhooks, searchHandler handlers.Search) *gin.Engine {
 	// Initialize a new Gin router without any middleware by default.
 	r := gin.New()
 
@@ -22,8 +22,8 @@ func CreateRouter(handler handlers.Indexation) *gin.Engine {
 	corsConfig := cors.Config{
 		AllowOrigins:     []string{"*"}, // Change this to specific domains if needed
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", traceIDHeader},
+		ExposeHeaders:    []string{"Content-Length", traceIDHeader},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			return true
@@ -34,6 +34,9 @@ func CreateRouter(handler handlers.Indexation) *gin.Engine {
 	// Apply the CORS middleware with the custom configuration
 	r.Use(cors.New(corsConfig))
 
+	// Attach a trace identifier and a logger to every request
+	r.Use(RequestContextMiddleware())
+
 	// Health check route.
 	r.GET("/health", health())
 
@@ -43,6 +46,16 @@ func CreateRouter(handler handlers.Indexation) *gin.Engine {
 	// Set up the route for Swagger documentation.
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
+	// Define public routes for searching the latest indexes.
+	search := r.Group("/search")
+	{
+		search.GET("", searchHandler.Federate())
+		search.GET("/suggest", searchHandler.Suggest())
+		search.GET("/programs", searchHandler.Programs())
+		search.GET("/episodes", searchHandler.Episodes())
+		search.GET("/media", searchHandler.Medias())
+	}
+
 	// Define private routes that require authentication.
 	private := r.Group("/private")
 	private.Use(TokenValidatorMiddleware())
@@ -58,6 +71,33 @@ func CreateRouter(handler handlers.Indexation) *gin.Engine {
 			indexation.POST("/episodes", handler.Episodes())
 			indexation.POST("/medias", handler.Medias())
 			indexation.POST("/all", handler.All())
+
+			// Routes for following and cancelling indexation jobs.
+			indexation.GET("/jobs", handler.Jobs())
+			indexation.GET("/jobs/:id", handler.Job())
+			indexation.POST("/jobs/:id/cancel", handler.CancelJob())
+			indexation.GET("/jobs/:id/events", handler.JobEvents())
+			indexation.GET("/history", handler.History())
+
+			// Routes for checking the consistency of the indexes with the database.
+			indexation.POST("/:type/verify", handler.Verify())
+			indexation.GET("/:type/diff", handler.Diff())
+			indexation.GET("/integrity", handler.Integrity())
+
+			// Routes for managing the indexation scheduler.
+			indexation.GET("/schedule", schedulingHandler.Get())
+			indexation.POST("/schedule/enable", schedulingHandler.Enable())
+			indexation.POST("/schedule/disable", schedulingHandler.Disable())
+
+			// Routes for following the outbound webhooks.
+			indexation.GET("/webhooks/deliveries", webhooksHandler.Deliveries())
+		}
+
+		// Routes for managing the synonyms and stopwords of the search analyzers.
+		search := private.Group("/search")
+		{
+			search.GET("/synonyms", handler.SearchTerms())
+			search.POST("/synonyms", handler.UpdateSearchTerms())
 		}
 	}
 
@@ -0,0 +1,23 @@
+-- Indexation history, one row per job.
+CREATE TABLE indexer_job (
+    id            VARCHAR(36)  NOT NULL PRIMARY KEY,
+    docsType      VARCHAR(32)  NOT NULL,
+    mode          VARCHAR(16)  NOT NULL,
+    triggerSource VARCHAR(32)  NOT NULL,
+    caller        VARCHAR(255) NOT NULL,
+    indexName     VARCHAR(255) NOT NULL,
+    status        VARCHAR(16)  NOT NULL,
+    reason        VARCHAR(16)  NOT NULL,
+    errorMessage  TEXT         NOT NULL,
+    phase         VARCHAR(16)  NOT NULL,
+    rowsRead      INT          NOT NULL,
+    documents     INT          NOT NULL,
+    invalid       INT          NOT NULL,
+    flushes       INT          NOT NULL,
+    indexed       INT          NOT NULL,
+    failures      INT          NOT NULL,
+    startedAt     DATETIME(3)  NOT NULL,
+    finishedAt    DATETIME(3)  NULL,
+    INDEX indexer_job_startedAt (startedAt),
+    INDEX indexer_job_docsType_startedAt (docsType, startedAt)
+);
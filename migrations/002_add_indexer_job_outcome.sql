@@ -0,0 +1,6 @@
+-- Outcome counters and data-quality report of the jobs.
+ALTER TABLE indexer_job
+    ADD COLUMN removed      INT  NOT NULL DEFAULT 0 AFTER failures,
+    ADD COLUMN staleSkipped INT  NOT NULL DEFAULT 0 AFTER removed,
+    ADD COLUMN dropped      INT  NOT NULL DEFAULT 0 AFTER staleSkipped,
+    ADD COLUMN quality      JSON NULL AFTER dropped;
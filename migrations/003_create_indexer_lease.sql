@@ -0,0 +1,6 @@
+-- Leases shared by the replicas, one row per lease name.
+CREATE TABLE indexer_lease (
+    name      VARCHAR(64)  NOT NULL PRIMARY KEY,
+    holder    VARCHAR(255) NOT NULL,
+    expiresAt DATETIME(3)  NOT NULL
+);
@@ -0,0 +1,7 @@
+-- Synonyms and stopwords of the search analyzers, in a single row.
+CREATE TABLE indexer_search_terms (
+    id        TINYINT     NOT NULL PRIMARY KEY,
+    synonyms  JSON        NOT NULL,
+    stopwords JSON        NOT NULL,
+    updatedAt DATETIME(3) NOT NULL
+);
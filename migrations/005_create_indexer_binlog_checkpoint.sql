@@ -0,0 +1,7 @@
+-- Binlog positions reached by the change data capture, one row per checkpoint name.
+CREATE TABLE indexer_binlog_checkpoint (
+    name      VARCHAR(64)  NOT NULL PRIMARY KEY,
+    file      VARCHAR(255) NOT NULL,
+    position  INT UNSIGNED NOT NULL,
+    updatedAt DATETIME(3)  NOT NULL
+);
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -11,6 +12,12 @@ type FunctionToRetry func() (interface{}, error)
 // ExecuteWithBackoffRetry execute the function passed in parameters with a number of retries and a fixed backoff delay.
 // It returns the number of function calls (= 1 if everything was ok) and the error only if none of the calls worked
 func ExecuteWithBackoffRetry(function FunctionToRetry, maxRetry int, delay time.Duration) (interface{}, int, error) {
+	return ExecuteWithBackoffRetryContext(context.Background(), function, maxRetry, delay)
+}
+
+// ExecuteWithBackoffRetryContext behaves like ExecuteWithBackoffRetry but stops retrying as soon as the context is done,
+// in which case the context error is returned.
+func ExecuteWithBackoffRetryContext(ctx context.Context, function FunctionToRetry, maxRetry int, delay time.Duration) (interface{}, int, error) {
 	ticker := time.NewTicker(delay)
 	defer ticker.Stop()
 	result, err := function()
@@ -22,6 +29,8 @@ func ExecuteWithBackoffRetry(function FunctionToRetry, maxRetry int, delay time.
 
 		for ; nbTry <= maxRetry && !isNextCallWorked; nbTry++ {
 			select {
+			case <-ctx.Done():
+				return result, nbTry, ctx.Err()
 			case <-ticker.C:
 				{
 					result, err = function()
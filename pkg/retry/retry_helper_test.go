@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -25,3 +26,14 @@ func Test_BackoffRetry_WhenKo(t *testing.T) {
 	assert.Equal(t, 3, nbTry, "Should be tried 3 times since it's ko and there are 2 retries")
 	assert.Error(t, err, "Should be ko")
 }
+
+func Test_BackoffRetry_WhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	_, nbTry, err := ExecuteWithBackoffRetryContext(ctx, func() (interface{}, error) {
+		cancel()
+		return nil, errors.New("Fake error")
+	}, 2, time.Duration(100)*time.Millisecond)
+
+	assert.Equal(t, 1, nbTry, "Should not be retried once the context is cancelled")
+	assert.ErrorIs(t, err, context.Canceled)
+}
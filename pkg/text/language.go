@@ -0,0 +1,51 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are frequent words of each language detected, which rarely appear in texts written in the other ones.
+var stopwords = map[string]map[string]bool{
+	"fr": set("le", "la", "les", "des", "du", "un", "une", "et", "est", "dans", "pour", "pas", "que", "qui", "sur",
+		"au", "aux", "avec", "ce", "cette", "ces", "son", "sa", "ses", "ne", "il", "elle", "nous", "vous", "ils", "mais", "où"),
+	"en": set("the", "and", "of", "to", "is", "that", "for", "it", "with", "as", "was", "on", "are", "be", "this",
+		"by", "from", "at", "or", "have", "not", "you", "they", "his", "her", "which", "what", "who", "will", "an"),
+}
+
+// DetectLanguage returns the language of the text among the given ones, by counting their stopwords in it,
+// or an empty string if the text holds no stopword or as many of two languages. Languages without stopwords are ignored.
+func DetectLanguage(text string, languages []string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	detected, best, tie := "", 0, false
+	for _, language := range languages {
+		score := 0
+		for _, word := range words {
+			if stopwords[language][word] {
+				score++
+			}
+		}
+		switch {
+		case score > best:
+			detected, best, tie = language, score, false
+		case score == best && score > 0:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return detected
+}
+
+// set returns a set of the given words.
+func set(words ...string) map[string]bool {
+	s := make(map[string]bool, len(words))
+	for _, word := range words {
+		s[word] = true
+	}
+	return s
+}
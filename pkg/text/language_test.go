@@ -0,0 +1,15 @@
+package text
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_DetectLanguage_WhenTextHasStopwords(t *testing.T) {
+	languages := []string{"fr", "en"}
+
+	assert.Equal(t, "fr", DetectLanguage("L'émission des auditeurs et de la rédaction", languages))
+	assert.Equal(t, "en", DetectLanguage("The show of the listeners and the newsroom", languages))
+	assert.Equal(t, "", DetectLanguage("Podcast", languages), "A text without stopwords should not be detected")
+	assert.Equal(t, "", DetectLanguage("Le show", []string{"en"}), "Only the given languages should be detected")
+}
@@ -0,0 +1,96 @@
+// Package text provides the normalization of the free text written in the CMS editor.
+package text
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// ellipsis ends the truncated texts.
+const ellipsis = "…"
+
+// skippedElements are the elements whose content is not text.
+var skippedElements = map[string]bool{"script": true, "style": true}
+
+// PlainText returns the text of an HTML fragment: the markup is stripped, the content of the script and style elements
+// is dropped, the entities are decoded and the runs of whitespace are collapsed into single spaces.
+// Text without markup is only decoded and collapsed.
+func PlainText(fragment string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(stripTags(fragment))), " ")
+}
+
+// Truncate returns the text cut to at most maxLength characters, ellipsis included, at a word boundary when there is one.
+// The text is returned as is if it is short enough, or if maxLength is not positive.
+func Truncate(text string, maxLength int) string {
+	if maxLength <= 0 || utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:maxLength-1])
+	// The last word is dropped, unless it is cut at its end
+	if i := strings.LastIndexByte(cut, ' '); i > 0 && runes[maxLength-1] != ' ' {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ") + ellipsis
+}
+
+// stripTags replaces the tags of an HTML fragment by spaces, so that the text of adjacent elements is not glued together,
+// and drops the comments and the content of the skipped elements. A '<' not starting a tag is kept as text.
+func stripTags(fragment string) string {
+	var text strings.Builder
+	for len(fragment) > 0 {
+		start := strings.IndexByte(fragment, '<')
+		if start < 0 {
+			text.WriteString(fragment)
+			break
+		}
+		text.WriteString(fragment[:start])
+		fragment = fragment[start:]
+
+		if strings.HasPrefix(fragment, "<!--") {
+			end := strings.Index(fragment, "-->")
+			if end < 0 {
+				break
+			}
+			fragment = fragment[end+len("-->"):]
+			text.WriteByte(' ')
+			continue
+		}
+		name := tagName(fragment)
+		end := strings.IndexByte(fragment, '>')
+		if name == "" || end < 0 {
+			text.WriteByte('<')
+			fragment = fragment[1:]
+			continue
+		}
+		opening := fragment[1] != '/'
+		fragment = fragment[end+1:]
+		text.WriteByte(' ')
+
+		if opening && skippedElements[name] {
+			closing := strings.Index(strings.ToLower(fragment), "</"+name)
+			if closing < 0 {
+				break
+			}
+			fragment = fragment[closing:]
+		}
+	}
+	return text.String()
+}
+
+// tagName returns the lower-case name of the tag starting the fragment, or an empty string if it does not start a tag.
+// Closing tags and declarations have a name too.
+func tagName(fragment string) string {
+	name := strings.TrimLeft(fragment[1:], "/!")
+	end := 0
+	for end < len(name) && (isLetter(name[end]) || (end > 0 && name[end] >= '0' && name[end] <= '9')) {
+		end++
+	}
+	return strings.ToLower(name[:end])
+}
+
+// isLetter reports whether the byte is an ASCII letter.
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
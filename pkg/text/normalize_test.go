@@ -0,0 +1,20 @@
+package text
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_PlainText_WhenTextHasMarkup(t *testing.T) {
+	fragment := "<p>Tom &amp; Jerry<br/>are   back</p>\n<!-- draft --><script>alert('x')</script><ul><li>1 &lt; 2</li></ul>"
+
+	assert.Equal(t, "Tom & Jerry are back 1 < 2", PlainText(fragment))
+	assert.Equal(t, "a < b", PlainText("a < b"), "A '<' not starting a tag should be kept")
+}
+
+func Test_Truncate_WhenTextIsTooLong(t *testing.T) {
+	assert.Equal(t, "The daily…", Truncate("The daily show of the news", 12))
+	assert.Equal(t, "The daily…", Truncate("The daily show", 11), "A word cut at its end should be kept")
+	assert.Equal(t, "Short", Truncate("Short", 12))
+	assert.Equal(t, "Short", Truncate("Short", 0))
+}